@@ -4,6 +4,34 @@ import (
 	"fmt"
 )
 
+// InvalidChunkError ...
+type InvalidChunkError struct {
+	message string
+}
+
+func (e InvalidChunkError) Error() string {
+	return e.message
+}
+func NewInvalidChunkError(msg string) *InvalidChunkError {
+	return &InvalidChunkError{
+		message: fmt.Sprintf("invalid chunk: %s", msg),
+	}
+}
+
+// InvalidDigestSizeError ...
+type InvalidDigestSizeError struct {
+	message string
+}
+
+func (e InvalidDigestSizeError) Error() string {
+	return e.message
+}
+func NewInvalidDigestSizeError(msg string) *InvalidDigestSizeError {
+	return &InvalidDigestSizeError{
+		message: fmt.Sprintf("invalid digest size: %s", msg),
+	}
+}
+
 // InvalidEngineError ...
 type InvalidEngineError struct {
 	message string
@@ -32,6 +60,20 @@ func NewInvalidJSONError(msg string) *InvalidJSONError {
 	}
 }
 
+// InvalidKeyError ...
+type InvalidKeyError struct {
+	message string
+}
+
+func (e InvalidKeyError) Error() string {
+	return e.message
+}
+func NewInvalidKeyError(msg string) *InvalidKeyError {
+	return &InvalidKeyError{
+		message: fmt.Sprintf("invalid key: %s", msg),
+	}
+}
+
 // InvalidMerkleProofError ...
 type InvalidMerkleProofError struct {
 	message string
@@ -46,6 +88,34 @@ func NewInvalidMerkleProofError(msg string) *InvalidMerkleProofError {
 	}
 }
 
+// InvalidMultihashError ...
+type InvalidMultihashError struct {
+	message string
+}
+
+func (e InvalidMultihashError) Error() string {
+	return e.message
+}
+func NewInvalidMultihashError(msg string) *InvalidMultihashError {
+	return &InvalidMultihashError{
+		message: fmt.Sprintf("invalid multihash: %s", msg),
+	}
+}
+
+// InvalidRLPError ...
+type InvalidRLPError struct {
+	message string
+}
+
+func (e InvalidRLPError) Error() string {
+	return e.message
+}
+func NewInvalidRLPError(msg string) *InvalidRLPError {
+	return &InvalidRLPError{
+		message: fmt.Sprintf("invalid RLP: %s", msg),
+	}
+}
+
 // TreeNotBuiltError ...
 type TreeNotBuiltError struct {
 	message string
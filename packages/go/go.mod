@@ -4,13 +4,16 @@ go 1.24.0
 
 require (
 	github.com/cyrildever/go-utls v1.10.10
+	golang.org/x/crypto v0.46.0
 	gotest.tools v2.2.0+incompatible
+	lukechampine.com/blake3 v1.4.1
 )
 
 require (
 	github.com/fatih/color v1.18.0 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
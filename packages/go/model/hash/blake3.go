@@ -0,0 +1,101 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	stdhash "hash"
+	"io"
+
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"lukechampine.com/blake3"
+	"lukechampine.com/blake3/bao"
+)
+
+// BLAKE3_CHUNK_SIZE is the size in bytes of the leaves of BLAKE3's own internal tree
+const BLAKE3_CHUNK_SIZE = 1024
+
+// Blake3Outboard hashes the passed data of the passed size and returns its BLAKE3 hash along with the outboard encoding of BLAKE3's internal chunk tree,
+// ie. the data length followed by every intermediate chaining value, which is what inclusion proofs of chunks are extracted from.
+func Blake3Outboard(data io.Reader, size int64) (root Hash, outboard []byte, err error) {
+	buf := &bufferAt{buf: make([]byte, bao.EncodedSize(int(size), 0, true))}
+	r, err := bao.Encode(buf, data, size, 0, true)
+	if err != nil {
+		return
+	}
+	return r[:], buf.buf, nil
+}
+
+// Blake3ChunkSlice builds the verifiable slice of the chunk at the passed index using the outboard encoding of the whole data,
+// ie. the chunk prefixed with the chaining values needed to rebuild the BLAKE3 hash.
+func Blake3ChunkSlice(chunk []byte, outboard []byte, index int) (slice []byte, err error) {
+	offset, length, err := blake3ChunkBounds(outboard, index)
+	if err != nil {
+		return
+	}
+	if uint64(len(chunk)) != length {
+		err = exception.NewInvalidChunkError(fmt.Sprintf("expected %d bytes at index %d, got %d", length, index, len(chunk)))
+		return
+	}
+	var dst bytes.Buffer
+	if err = bao.ExtractSlice(&dst, bytes.NewReader(chunk), bytes.NewReader(outboard), 0, offset, length); err != nil {
+		return
+	}
+	slice = dst.Bytes()
+	return
+}
+
+// VerifyBlake3ChunkSlice checks the passed slice against the passed BLAKE3 hash and returns the verified chunk at the passed index if it's valid
+func VerifyBlake3ChunkSlice(slice []byte, index int, root Hash) (chunk []byte, ok bool) {
+	if len(root) != blake3Size {
+		return
+	}
+	offset, length, err := blake3ChunkBounds(slice, index)
+	if err != nil {
+		return
+	}
+	var r [blake3Size]byte
+	copy(r[:], root)
+	return bao.VerifySlice(slice, 0, offset, length, r)
+}
+
+//--- utility
+
+const blake3Size = 32
+
+func newBlake3(key []byte) (stdhash.Hash, error) {
+	if len(key) == 0 {
+		return blake3.New(blake3Size, nil), nil
+	}
+	if len(key) != blake3Size {
+		return nil, fmt.Errorf("blake3 key must be %d bytes long", blake3Size)
+	}
+	return blake3.New(blake3Size, key), nil
+}
+
+// blake3ChunkBounds reads the data length from the first eight bytes of an encoding to find the boundaries of the chunk at the passed index
+func blake3ChunkBounds(encoding []byte, index int) (offset, length uint64, err error) {
+	if len(encoding) < 8 {
+		err = exception.NewInvalidChunkError("missing data length")
+		return
+	}
+	dataLen := binary.LittleEndian.Uint64(encoding[:8])
+	offset = uint64(index) * BLAKE3_CHUNK_SIZE
+	if index < 0 || offset >= dataLen && !(offset == 0 && dataLen == 0) {
+		err = exception.NewInvalidChunkError(fmt.Sprintf("index %d out of range", index))
+		return
+	}
+	length = min(BLAKE3_CHUNK_SIZE, dataLen-offset)
+	return
+}
+
+type bufferAt struct {
+	buf []byte
+}
+
+func (b *bufferAt) WriteAt(p []byte, off int64) (int, error) {
+	if copy(b.buf[off:], p) != len(p) {
+		return 0, io.ErrShortWrite
+	}
+	return len(p), nil
+}
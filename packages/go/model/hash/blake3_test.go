@@ -0,0 +1,61 @@
+package hash_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"gotest.tools/assert"
+)
+
+// TestBlake3ChunkSlice ...
+func TestBlake3ChunkSlice(t *testing.T) {
+	data := make([]byte, 5*hash.BLAKE3_CHUNK_SIZE+100)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	root, outboard, err := hash.Blake3Outboard(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blake3, err := hash.BuildFunction(hash.BLAKE3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, root, blake3(data))
+
+	chunk := data[2*hash.BLAKE3_CHUNK_SIZE : 3*hash.BLAKE3_CHUNK_SIZE]
+	slice, err := hash.Blake3ChunkSlice(chunk, outboard, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, ok := hash.VerifyBlake3ChunkSlice(slice, 2, root)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, verified, chunk)
+
+	// Wrong index
+	_, ok = hash.VerifyBlake3ChunkSlice(slice, 3, root)
+	assert.Assert(t, !ok)
+
+	// Tampered chunk
+	tampered := append([]byte{}, slice...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, ok = hash.VerifyBlake3ChunkSlice(tampered, 2, root)
+	assert.Assert(t, !ok)
+
+	// Last partial chunk
+	last := data[5*hash.BLAKE3_CHUNK_SIZE:]
+	slice, err = hash.Blake3ChunkSlice(last, outboard, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, ok = hash.VerifyBlake3ChunkSlice(slice, 5, root)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, verified, last)
+
+	_, err = hash.Blake3ChunkSlice(chunk, outboard, 5)
+	assert.Error(t, err, "invalid chunk: expected 100 bytes at index 5, got 1024")
+
+	_, err = hash.Blake3ChunkSlice(chunk, outboard, 6)
+	assert.Error(t, err, "invalid chunk: index 6 out of range")
+}
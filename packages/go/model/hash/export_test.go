@@ -0,0 +1,6 @@
+package hash
+
+// Unregister removes the passed engine from the registry, so that tests may register it again, eg. with `go test -count=2`
+func Unregister(name string) {
+	unregister(name)
+}
@@ -1,8 +1,10 @@
 package hash
 
 import (
-	"crypto/sha256"
-	"regexp"
+	"crypto/hmac"
+	"fmt"
+	stdhash "hash"
+	"io"
 
 	"github.com/cyrildever/merkle-trees/packages/go/exception"
 )
@@ -10,36 +12,165 @@ import (
 // Function
 type Function func([]byte) Hash
 
-// Supported hash functions
+// StreamFunction is a hash function incrementally consuming its input, eg. to hash large files without loading them into memory
+type StreamFunction func(io.Reader) (Hash, error)
+
+// Built-in hash functions
 const (
 	SHA_256 = "sha-256"
-)
+	SHA_384 = "sha-384"
+	SHA_512 = "sha-512"
+
+	SHA3_256 = "sha3-256"
+	SHA3_512 = "sha3-512"
+
+	// KECCAK_256 is the legacy Keccak-256 as used by Ethereum, ie. not the standardised SHA3-256
+	KECCAK_256 = "keccak-256"
+
+	BLAKE2B_256 = "blake2b-256"
+	BLAKE2B_512 = "blake2b-512"
+	BLAKE2S_256 = "blake2s-256"
 
-var (
-	regexSha256 = regexp.MustCompile(`^[a-f0-9]{64}$`)
+	BLAKE3 = "blake3"
+
+	// POSEIDON_BN254 is the circomlib-compatible Poseidon hash over the BN254 scalar field: it only hashes 32-byte big-endian field elements
+	// (see `PoseidonEncode()` for any other data) and its digests are field elements themselves
+	POSEIDON_BN254 = "poseidon-bn254"
 )
 
 // BuildFunction ...
 func BuildFunction(engine string, doubleHash ...bool) (fn Function, err error) {
-	doDoubleHash := false
-	if len(doubleHash) == 1 && doubleHash[0] {
-		doDoubleHash = true
-	}
-	switch engine {
-	case SHA_256:
-		return func(item []byte) Hash {
-			h := sha256.New()
-			_, _ = h.Write(item)
-			if doDoubleHash {
-				h2 := sha256.New()
-				_, _ = h2.Write(h.Sum(nil))
-				return h2.Sum(nil)
-			} else {
-				return h.Sum(nil)
-			}
+	return BuildKeyedFunction(engine, nil, doubleHash...)
+}
+
+// BuildKeyedFunction returns the hash function for the passed engine using the passed key, if any.
+// Engines registered through `RegisterKeyed()`, eg. BLAKE2 and BLAKE3, use their native keyed mode, all others are turned into HMAC,
+// eg. HMAC-SHA-256 for the "sha-256" engine.
+func BuildKeyedFunction(engine string, key []byte, doubleHash ...bool) (fn Function, err error) {
+	newHash, err := hasherFor(engine, key)
+	if err != nil {
+		return
+	}
+	return buildFrom(newHash, len(doubleHash) == 1 && doubleHash[0]), nil
+}
+
+// BuildStreamFunction returns the hash function for the passed engine that incrementally consumes its input
+func BuildStreamFunction(engine string, doubleHash ...bool) (fn StreamFunction, err error) {
+	return BuildKeyedStreamFunction(engine, nil, doubleHash...)
+}
+
+// BuildKeyedStreamFunction is the streaming equivalent of `BuildKeyedFunction()`
+func BuildKeyedStreamFunction(engine string, key []byte, doubleHash ...bool) (fn StreamFunction, err error) {
+	newHash, err := hasherFor(engine, key)
+	if err != nil {
+		return
+	}
+	return buildStreamFrom(newHash, len(doubleHash) == 1 && doubleHash[0]), nil
+}
+
+// MIN_TRUNCATED_DIGEST_SIZE is the minimum length in bytes of a truncated digest, ie. 128 bits
+const MIN_TRUNCATED_DIGEST_SIZE = 16
+
+// TruncateFunction returns the passed hash function only keeping the first `size` bytes of its digests.
+//
+// IMPORTANT: Truncating a digest of n bits down to t bits lowers its collision resistance to t/2 bits and its (second-)preimage resistance to t bits,
+// eg. a 16-byte digest only offers 64 bits of security against someone able to choose the data of two leaves.
+func TruncateFunction(fn Function, size int) Function {
+	return func(item []byte) Hash {
+		h := fn(item)
+		if len(h) > size {
+			return h[:size]
+		}
+		return h
+	}
+}
+
+// TruncateStreamFunction is the streaming equivalent of `TruncateFunction()`
+func TruncateStreamFunction(fn StreamFunction, size int) StreamFunction {
+	return func(r io.Reader) (Hash, error) {
+		h, err := fn(r)
+		if err != nil || len(h) <= size {
+			return h, err
+		}
+		return h[:size], nil
+	}
+}
+
+// ValidateTruncatedSize checks that the digests of the passed engine may be truncated to the passed size in bytes
+func ValidateTruncatedSize(engine string, size int) error {
+	eng, err := lookup(engine)
+	if err != nil {
+		return err
+	}
+	if eng.check != nil {
+		return exception.NewInvalidDigestSizeError(fmt.Sprintf("%s digests can't be truncated", engine))
+	}
+	if size < MIN_TRUNCATED_DIGEST_SIZE || size > eng.digestSize {
+		return exception.NewInvalidDigestSizeError(fmt.Sprintf("%d not in [%d, %d] for %s", size, MIN_TRUNCATED_DIGEST_SIZE, eng.digestSize, engine))
+	}
+	return nil
+}
+
+// GetDigestSize returns the length in bytes of the digests produced by the passed engine
+func GetDigestSize(engine string) (size int, err error) {
+	eng, err := lookup(engine)
+	if err != nil {
+		return
+	}
+	return eng.digestSize, nil
+}
+
+//--- utility
+
+func buildFrom(newHash func() stdhash.Hash, doDoubleHash bool) Function {
+	return func(item []byte) Hash {
+		h := newHash()
+		_, _ = h.Write(item)
+		if doDoubleHash {
+			h2 := newHash()
+			_, _ = h2.Write(h.Sum(nil))
+			return h2.Sum(nil)
+		} else {
+			return h.Sum(nil)
+		}
+	}
+}
+
+func buildStreamFrom(newHash func() stdhash.Hash, doDoubleHash bool) StreamFunction {
+	return func(r io.Reader) (Hash, error) {
+		h := newHash()
+		if _, err := io.Copy(h, r); err != nil {
+			return nil, err
+		}
+		if doDoubleHash {
+			h2 := newHash()
+			_, _ = h2.Write(h.Sum(nil))
+			return h2.Sum(nil), nil
+		} else {
+			return h.Sum(nil), nil
+		}
+	}
+}
+
+func hasherFor(engine string, key []byte) (newHash func() stdhash.Hash, err error) {
+	eng, err := lookup(engine)
+	if err != nil {
+		return
+	}
+	if len(key) != 0 && !eng.isKeyed {
+		return func() stdhash.Hash {
+			return hmac.New(func() stdhash.Hash {
+				h, _ := eng.newHash(nil)
+				return h
+			}, key)
 		}, nil
-	default:
-		err = exception.NewInvalidEngineError(engine)
 	}
-	return
+	if _, e := eng.newHash(key); e != nil {
+		err = exception.NewInvalidKeyError(e.Error())
+		return
+	}
+	return func() stdhash.Hash {
+		h, _ := eng.newHash(key)
+		return h
+	}, nil
 }
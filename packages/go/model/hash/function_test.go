@@ -1,6 +1,8 @@
 package hash_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	utls "github.com/cyrildever/go-utls/common/utils"
@@ -26,6 +28,245 @@ func TestBuildFunction(t *testing.T) {
 	found = doubleSha256([]byte("test"))
 	assert.Equal(t, utls.ToHex(found), expected)
 
+	expected = "768412320f7b0aa5812fce428dc4706b3cae50e02a64caa16a782249bfe8efc4b7ef1ccb126255d196047dfedf17a0a9"
+	sha384, err := hash.BuildFunction(hash.SHA_384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = sha384([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff"
+	sha512, err := hash.BuildFunction(hash.SHA_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = sha512([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "faadcaf60afd35dfcdb5e9ea0d0a0531f6338c62187cff37a1efe11f1d41a34879731bc9db49df75aecf5d582ad09b5c6ded2d86bd1f07c11bd755d1fccc81fe"
+	doubleSha512, err := hash.BuildFunction(hash.SHA_512, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = doubleSha512([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "36f028580bb02cc8272a9a020f4200e346e276ae664e45ee80745574e2f5ab80"
+	sha3_256, err := hash.BuildFunction(hash.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = sha3_256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "946f77802240fe1c0f070a36db896022be809609f79ccbf52679c525bd0681209569be6210a2119973daf894fdc65ea0d219522cf569ab6560b5c65c0cf22015"
+	doubleSha3_512, err := hash.BuildFunction(hash.SHA3_512, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = doubleSha3_512([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "9c22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658"
+	keccak256, err := hash.BuildFunction(hash.KECCAK_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = keccak256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "928b20366943e2afd11ebc0eae2e53a93bf177a4fcf35bcc64d503704e65e202"
+	blake2b256, err := hash.BuildFunction(hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake2b256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "a71079d42853dea26e453004338670a53814b78137ffbed07603a41d76a483aa9bc33b582f77d30a65e6f29a896c0411f38312e1d66e0bf16386c86a89bea572"
+	blake2b512, err := hash.BuildFunction(hash.BLAKE2B_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake2b512([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "f308fc02ce9172ad02a7d75800ecfc027109bc67987ea32aba9b8dcc7b10150e"
+	blake2s256, err := hash.BuildFunction(hash.BLAKE2S_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake2s256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "4878ca0425c739fa427f7eda20fe845f6b2e46ba5fe2a14df5b1e32f50603215"
+	blake3, err := hash.BuildFunction(hash.BLAKE3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake3([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
 	_, err = hash.BuildFunction("wrong-engine")
 	assert.Error(t, err, "invalid engine: wrong-engine")
 }
+
+// TestBuildKeyedFunction ...
+func TestBuildKeyedFunction(t *testing.T) {
+	key := []byte("secret")
+
+	expected := "8854d9d08f16bd806bbc2890b5f8a21dc01865d9011f5995ae2f545f8f52edd3"
+	blake2b256, err := hash.BuildKeyedFunction(hash.BLAKE2B_256, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := blake2b256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "696a8c4fb88f64c6cedc2bcd55422d01f774af977094a8d18e62bfb17362fa9b"
+	blake2s256, err := hash.BuildKeyedFunction(hash.BLAKE2S_256, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake2s256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "89cd1c62de8ea7b1cc2851789f33769d6f0276ab77e48f2920ca8c11d2919237"
+	blake3, err := hash.BuildKeyedFunction(hash.BLAKE3, []byte("whats the Elvish word for friend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = blake3([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	_, err = hash.BuildKeyedFunction(hash.BLAKE3, key)
+	assert.ErrorContains(t, err, "invalid key")
+
+	_, err = hash.BuildKeyedFunction(hash.BLAKE2S_256, make([]byte, 33))
+	assert.ErrorContains(t, err, "invalid key")
+
+	expected = "0329a06b62cd16b33eb6792be8c60b158d89a2ee3a876fce9a881ebb488c0914"
+	hmacSha256, err := hash.BuildKeyedFunction(hash.SHA_256, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = hmacSha256([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+
+	expected = "f8a4f0a209167bc192a1bffaa01ecdb09e06c57f96530d92ec9ccea0090d290e55071306d6b654f26ae0c8721f7e48a2d7130b881151f2cec8d61d941a6be88a"
+	hmacSha512, err := hash.BuildKeyedFunction(hash.SHA_512, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found = hmacSha512([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), expected)
+}
+
+// TestGetDigestSize ...
+func TestGetDigestSize(t *testing.T) {
+	size, err := hash.GetDigestSize(hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 32)
+
+	size, err = hash.GetDigestSize(hash.SHA_384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 48)
+
+	size, err = hash.GetDigestSize(hash.SHA_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 64)
+
+	size, err = hash.GetDigestSize(hash.SHA3_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 64)
+
+	size, err = hash.GetDigestSize(hash.BLAKE2S_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 32)
+
+	_, err = hash.GetDigestSize("wrong-engine")
+	assert.Error(t, err, "invalid engine: wrong-engine")
+}
+
+// TestBuildStreamFunction ...
+func TestBuildStreamFunction(t *testing.T) {
+	sha256, err := hash.BuildStreamFunction(hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := sha256(strings.NewReader("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(found), "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+
+	doubleSha256, err := hash.BuildStreamFunction(hash.SHA_256, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = doubleSha256(strings.NewReader("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(found), "954d5a49fd70d9b8bcdb35d252267829957f7ef7fa6c74f88419bdc5e82209f4")
+
+	hmacSha256, err := hash.BuildKeyedStreamFunction(hash.SHA_256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = hmacSha256(strings.NewReader("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(found), "0329a06b62cd16b33eb6792be8c60b158d89a2ee3a876fce9a881ebb488c0914")
+
+	_, err = sha256(failingReader{})
+	assert.Error(t, err, "read failure")
+
+	_, err = hash.BuildStreamFunction("wrong-engine")
+	assert.Error(t, err, "invalid engine: wrong-engine")
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failure")
+}
+
+// TestTruncateFunction ...
+func TestTruncateFunction(t *testing.T) {
+	sha256, err := hash.BuildFunction(hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := hash.TruncateFunction(sha256, 20)
+	assert.Equal(t, utls.ToHex(truncated([]byte("test"))), "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b")
+
+	stream, err := hash.BuildStreamFunction(hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := hash.TruncateStreamFunction(stream, 16)(strings.NewReader("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(found), "9f86d081884c7d659a2feaa0c55ad015")
+
+	assert.NilError(t, hash.ValidateTruncatedSize(hash.SHA_256, 16))
+	assert.NilError(t, hash.ValidateTruncatedSize(hash.SHA_256, 32))
+	assert.Error(t, hash.ValidateTruncatedSize(hash.SHA_256, 8), "invalid digest size: 8 not in [16, 32] for sha-256")
+	assert.Error(t, hash.ValidateTruncatedSize(hash.SHA_256, 33), "invalid digest size: 33 not in [16, 32] for sha-256")
+	assert.Error(t, hash.ValidateTruncatedSize(hash.POSEIDON_BN254, 16), "invalid digest size: poseidon-bn254 digests can't be truncated")
+	assert.Error(t, hash.ValidateTruncatedSize("wrong-engine", 16), "invalid engine: wrong-engine")
+}
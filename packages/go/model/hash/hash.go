@@ -12,14 +12,19 @@ type Hash = []byte
 // Hashes ...
 type Hashes = []Hash
 
-// IsCorrect ...
-func IsCorrect(h []byte, engine string) bool {
-	switch engine {
-	case SHA_256:
-		return regexSha256.MatchString(utls.ToHex(h))
-	default:
+// IsCorrect checks that the passed hash is a digest of the passed engine, or a digest truncated to the passed size if any (see `TruncateFunction()`)
+func IsCorrect(h []byte, engine string, truncatedSize ...int) bool {
+	eng, err := lookup(engine)
+	if err != nil {
 		return false
 	}
+	if len(truncatedSize) == 1 && truncatedSize[0] != 0 {
+		return ValidateTruncatedSize(engine, truncatedSize[0]) == nil && len(h) == truncatedSize[0]
+	}
+	if eng.check != nil {
+		return eng.check(h)
+	}
+	return eng.regex.MatchString(utls.ToHex(h))
 }
 
 // SortHashes lexicographically sort the passed hashes
@@ -20,6 +20,25 @@ func TestIsCorrectHash(t *testing.T) {
 
 	found = hash.IsCorrect(correct, "wrong-engine")
 	assert.Assert(t, !found)
+
+	correct512 := utls.Must(utls.FromHex("ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff"))
+	found = hash.IsCorrect(correct512, hash.SHA_512)
+	assert.Assert(t, found)
+
+	found = hash.IsCorrect(correct, hash.SHA_512)
+	assert.Assert(t, !found)
+
+	found = hash.IsCorrect(correct512[:48], hash.SHA_384)
+	assert.Assert(t, found)
+
+	found = hash.IsCorrect(correct[:20], hash.SHA_256, 20)
+	assert.Assert(t, found)
+
+	found = hash.IsCorrect(correct, hash.SHA_256, 20)
+	assert.Assert(t, !found)
+
+	found = hash.IsCorrect(correct[:8], hash.SHA_256, 8)
+	assert.Assert(t, !found)
 }
 
 // TestSortHashes ...
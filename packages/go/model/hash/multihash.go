@@ -0,0 +1,121 @@
+package hash
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+)
+
+// MULTIHASH is the pseudo-engine name used in stringified proofs whose hashes are self-describing multihashes
+const MULTIHASH = "multihash"
+
+// Multihash codes of the built-in engines, as defined in the multicodec table (https://github.com/multiformats/multicodec)
+const (
+	MULTIHASH_SHA_256     uint64 = 0x12
+	MULTIHASH_SHA_512     uint64 = 0x13
+	MULTIHASH_SHA3_512    uint64 = 0x14
+	MULTIHASH_SHA3_256    uint64 = 0x16
+	MULTIHASH_KECCAK_256  uint64 = 0x1b
+	MULTIHASH_BLAKE3      uint64 = 0x1e
+	MULTIHASH_SHA_384     uint64 = 0x20
+	MULTIHASH_BLAKE2B_256 uint64 = 0xb220
+	MULTIHASH_BLAKE2B_512 uint64 = 0xb240
+	MULTIHASH_BLAKE2S_256 uint64 = 0xb260
+)
+
+// RegisterMultihashCode assigns the passed multihash code to the passed registered engine, provided the code isn't already used
+func RegisterMultihashCode(engine string, code uint64) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	eng, found := registry[engine]
+	if !found {
+		return exception.NewInvalidEngineError(engine)
+	}
+	for name, e := range registry {
+		if e.hasMultihash && e.multihashCode == code && name != engine {
+			return exception.NewInvalidEngineError(fmt.Sprintf("multihash code 0x%x already used by %s", code, name))
+		}
+	}
+	eng.multihashCode = code
+	eng.hasMultihash = true
+	return nil
+}
+
+// HasMultihashCode returns `true` if the passed engine has a multihash code
+func HasMultihashCode(engine string) bool {
+	eng, err := lookup(engine)
+	return err == nil && eng.hasMultihash
+}
+
+// ToMultihash encodes the passed digest of the passed engine as a multihash, ie. `<varint code><varint length><digest>`
+func ToMultihash(h Hash, engine string) (mh []byte, err error) {
+	eng, err := lookup(engine)
+	if err != nil {
+		return
+	}
+	if !eng.hasMultihash {
+		err = exception.NewInvalidMultihashError(fmt.Sprintf("no code for %s", engine))
+		return
+	}
+	if len(h) != eng.digestSize {
+		err = exception.NewInvalidMultihashError(fmt.Sprintf("wrong digest length for %s: %d", engine, len(h)))
+		return
+	}
+	mh = binary.AppendUvarint(nil, eng.multihashCode)
+	mh = binary.AppendUvarint(mh, uint64(len(h)))
+	mh = append(mh, h...)
+	return
+}
+
+// FromMultihash decodes the passed multihash, returning the digest and the name of the engine that produced it
+func FromMultihash(mh []byte) (h Hash, engine string, err error) {
+	h, engine, n, err := ReadMultihash(mh)
+	if err != nil {
+		return
+	}
+	if n != len(mh) {
+		err = exception.NewInvalidMultihashError("trailing bytes")
+	}
+	return
+}
+
+// ReadMultihash decodes the multihash at the start of the passed bytes, also returning the number of bytes it used,
+// which allows reading a concatenation of multihashes
+func ReadMultihash(b []byte) (h Hash, engine string, n int, err error) {
+	code, c := binary.Uvarint(b)
+	if c <= 0 {
+		err = exception.NewInvalidMultihashError("unreadable code")
+		return
+	}
+	length, l := binary.Uvarint(b[c:])
+	if l <= 0 {
+		err = exception.NewInvalidMultihashError("unreadable length")
+		return
+	}
+	engine, size, found := engineForMultihashCode(code)
+	if !found {
+		err = exception.NewInvalidMultihashError(fmt.Sprintf("unknown code 0x%x", code))
+		return
+	}
+	n = c + l + int(length)
+	if length != uint64(size) || len(b) < n {
+		err = exception.NewInvalidMultihashError(fmt.Sprintf("wrong digest length for %s: %d", engine, length))
+		return
+	}
+	h = b[c+l : n]
+	return
+}
+
+//--- utility
+
+func engineForMultihashCode(code uint64) (name string, digestSize int, found bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for n, e := range registry {
+		if e.hasMultihash && e.multihashCode == code {
+			return n, e.digestSize, true
+		}
+	}
+	return
+}
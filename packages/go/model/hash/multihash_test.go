@@ -0,0 +1,91 @@
+package hash_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"gotest.tools/assert"
+)
+
+// TestMultihash ...
+func TestMultihash(t *testing.T) {
+	digest := utls.Must(utls.FromHex("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"))
+	mh, err := hash.ToMultihash(digest, hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(mh), "12209f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+
+	found, engine, err := hash.FromMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, engine, hash.SHA_256)
+	assert.DeepEqual(t, found, digest)
+
+	// Two-byte varint code
+	blake2b, err := hash.BuildFunction(hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mh, err = hash.ToMultihash(blake2b([]byte("test")), hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(mh[:3]), "a0e402")
+	_, engine, err = hash.FromMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, engine, hash.BLAKE2B_256)
+
+	_, err = hash.ToMultihash(digest, hash.SHA_512)
+	assert.Error(t, err, "invalid multihash: wrong digest length for sha-512: 32")
+
+	_, err = hash.ToMultihash(digest, hash.POSEIDON_BN254)
+	assert.Error(t, err, "invalid multihash: no code for poseidon-bn254")
+
+	_, _, err = hash.FromMultihash(utls.Must(utls.FromHex("1240" + utls.ToHex(digest))))
+	assert.Error(t, err, "invalid multihash: wrong digest length for sha-256: 64")
+
+	_, _, err = hash.FromMultihash(utls.Must(utls.FromHex("0120" + utls.ToHex(digest))))
+	assert.Error(t, err, "invalid multihash: unknown code 0x1")
+
+	_, _, err = hash.FromMultihash(append(utls.Must(utls.FromHex("1220"+utls.ToHex(digest))), 0))
+	assert.Error(t, err, "invalid multihash: trailing bytes")
+}
+
+// TestRegisterMultihashCode ...
+func TestRegisterMultihashCode(t *testing.T) {
+	if err := hash.Register("test-mh-sha-224", sha256.Size224, sha256.New224); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { hash.Unregister("test-mh-sha-224") })
+	assert.Assert(t, !hash.HasMultihashCode("test-mh-sha-224"))
+
+	err := hash.RegisterMultihashCode("test-mh-sha-224", hash.MULTIHASH_SHA_256)
+	assert.Error(t, err, "invalid engine: multihash code 0x12 already used by sha-256")
+
+	if err = hash.RegisterMultihashCode("test-mh-sha-224", 0x1013); err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, hash.HasMultihashCode("test-mh-sha-224"))
+	sha224, err := hash.BuildFunction("test-mh-sha-224")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mh, err := hash.ToMultihash(sha224([]byte("test")), "test-mh-sha-224")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, engine, err := hash.FromMultihash(mh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, engine, "test-mh-sha-224")
+
+	err = hash.RegisterMultihashCode("unregistered", 0x1014)
+	assert.Error(t, err, "invalid engine: unregistered")
+}
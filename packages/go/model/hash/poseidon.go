@@ -0,0 +1,233 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"math/big"
+	"sync"
+)
+
+// POSEIDON_MAX_INPUTS is the maximum number of field elements a single Poseidon hash may take, as in circomlib
+const POSEIDON_MAX_INPUTS = 16
+
+// BN254_SCALAR_FIELD is the order of the scalar field of the BN254 (aka. alt_bn128) curve
+var BN254_SCALAR_FIELD, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+// Poseidon hashes the passed field elements of the BN254 scalar field, returning the same output as circomlib's `Poseidon(n)` template
+func Poseidon(inputs ...*big.Int) (*big.Int, error) {
+	if len(inputs) == 0 || len(inputs) > POSEIDON_MAX_INPUTS {
+		return nil, errors.New("invalid number of poseidon inputs")
+	}
+	for _, input := range inputs {
+		if input.Sign() < 0 || input.Cmp(BN254_SCALAR_FIELD) >= 0 {
+			return nil, errors.New("poseidon input not in field")
+		}
+	}
+	params := poseidonParametersFor(len(inputs) + 1)
+	return params.permute(inputs), nil
+}
+
+// PoseidonEncode encodes any data into a sequence of 32-byte big-endian field elements, each holding 31 bytes of data,
+// so that it could be hashed by the "poseidon-bn254" engine
+func PoseidonEncode(data []byte) []byte {
+	encoded := []byte{}
+	for len(data) > 0 {
+		n := min(31, len(data))
+		word := make([]byte, 32)
+		copy(word[32-n:], data[:n])
+		encoded = append(encoded, word...)
+		data = data[n:]
+	}
+	return encoded
+}
+
+//--- utility
+
+// poseidonHash is the `hash.Hash` implementation used by the engine registry: written data is split into 32-byte big-endian field elements,
+// so that a node of a Merkle tree is the 2-to-1 Poseidon hash of its children.
+// If the written data doesn't hold between one and sixteen valid field elements, the sum is empty.
+type poseidonHash struct {
+	data []byte
+}
+
+func (p *poseidonHash) Write(b []byte) (int, error) {
+	p.data = append(p.data, b...)
+	return len(b), nil
+}
+
+func (p *poseidonHash) Sum(b []byte) []byte {
+	if len(p.data)%poseidonSize != 0 {
+		return b
+	}
+	var inputs []*big.Int
+	for i := 0; i < len(p.data); i += poseidonSize {
+		inputs = append(inputs, new(big.Int).SetBytes(p.data[i:i+poseidonSize]))
+	}
+	out, err := Poseidon(inputs...)
+	if err != nil {
+		return b
+	}
+	return append(b, out.FillBytes(make([]byte, poseidonSize))...)
+}
+
+func newPoseidon(key []byte) (stdhash.Hash, error) {
+	if len(key) != 0 {
+		return nil, fmt.Errorf("%s does not support keyed hashing", POSEIDON_BN254)
+	}
+	return &poseidonHash{}, nil
+}
+
+func (p *poseidonHash) Reset()         { p.data = nil }
+func (p *poseidonHash) Size() int      { return poseidonSize }
+func (p *poseidonHash) BlockSize() int { return poseidonSize }
+
+const poseidonSize = 32
+
+func isFieldElement(h Hash) bool {
+	return len(h) == poseidonSize && new(big.Int).SetBytes(h).Cmp(BN254_SCALAR_FIELD) < 0
+}
+
+// Parameters are generated using the Grain LFSR as in the reference implementation of Poseidon
+// (https://extgit.iaik.tugraz.at/krypto/hadeshash), which is what circomlib uses for BN254 with x^5 as S-box and eight full rounds
+const (
+	poseidonFullRounds = 8
+	poseidonFieldSize  = 254
+)
+
+var poseidonPartialRounds = []int{56, 57, 56, 60, 60, 63, 64, 63, 60, 66, 60, 65, 70, 60, 64, 68}
+
+type poseidonParameters struct {
+	t             int
+	partialRounds int
+	constants     []*big.Int
+	mds           [][]*big.Int
+}
+
+var (
+	poseidonCache   = map[int]*poseidonParameters{}
+	poseidonCacheMu sync.Mutex
+)
+
+func poseidonParametersFor(t int) *poseidonParameters {
+	poseidonCacheMu.Lock()
+	defer poseidonCacheMu.Unlock()
+	if params, found := poseidonCache[t]; found {
+		return params
+	}
+	params := &poseidonParameters{
+		t:             t,
+		partialRounds: poseidonPartialRounds[t-2],
+	}
+	grain := newGrain(t, poseidonFullRounds, params.partialRounds)
+	for range (poseidonFullRounds + params.partialRounds) * t {
+		c := grain.nextBits(poseidonFieldSize)
+		for c.Cmp(BN254_SCALAR_FIELD) >= 0 {
+			c = grain.nextBits(poseidonFieldSize)
+		}
+		params.constants = append(params.constants, c)
+	}
+	// Cauchy matrix built from 2t random distinct elements
+	xs := make([]*big.Int, 2*t)
+	for i := range xs {
+		xs[i] = new(big.Int).Mod(grain.nextBits(poseidonFieldSize), BN254_SCALAR_FIELD)
+	}
+	params.mds = make([][]*big.Int, t)
+	for i := range t {
+		params.mds[i] = make([]*big.Int, t)
+		for j := range t {
+			sum := new(big.Int).Add(xs[i], xs[t+j])
+			params.mds[i][j] = sum.ModInverse(sum.Mod(sum, BN254_SCALAR_FIELD), BN254_SCALAR_FIELD)
+		}
+	}
+	poseidonCache[t] = params
+	return params
+}
+
+func (p *poseidonParameters) permute(inputs []*big.Int) *big.Int {
+	state := make([]*big.Int, p.t)
+	state[0] = new(big.Int)
+	for i, input := range inputs {
+		state[i+1] = new(big.Int).Set(input)
+	}
+	tmp := new(big.Int)
+	for r := range poseidonFullRounds + p.partialRounds {
+		for i := range state {
+			state[i].Add(state[i], p.constants[r*p.t+i])
+		}
+		if r < poseidonFullRounds/2 || r >= poseidonFullRounds/2+p.partialRounds {
+			for i := range state {
+				pow5(state[i], tmp)
+			}
+		} else {
+			pow5(state[0], tmp)
+		}
+		mixed := make([]*big.Int, p.t)
+		for i := range mixed {
+			mixed[i] = new(big.Int)
+			for j := range state {
+				mixed[i].Add(mixed[i], tmp.Mul(p.mds[i][j], state[j]))
+			}
+			mixed[i].Mod(mixed[i], BN254_SCALAR_FIELD)
+		}
+		state = mixed
+	}
+	return state[0]
+}
+
+func pow5(x, tmp *big.Int) {
+	tmp.Mul(x, x).Mod(tmp, BN254_SCALAR_FIELD)
+	tmp.Mul(tmp, tmp).Mod(tmp, BN254_SCALAR_FIELD)
+	x.Mul(x, tmp).Mod(x, BN254_SCALAR_FIELD)
+}
+
+type grain struct {
+	state []byte
+}
+
+func newGrain(t, fullRounds, partialRounds int) *grain {
+	g := &grain{}
+	appendBits := func(value, size int) {
+		for i := size - 1; i >= 0; i-- {
+			g.state = append(g.state, byte((value>>i)&1))
+		}
+	}
+	appendBits(1, 2)                  // prime field
+	appendBits(0, 4)                  // x^alpha S-box
+	appendBits(poseidonFieldSize, 12) // field size
+	appendBits(t, 12)
+	appendBits(fullRounds, 10)
+	appendBits(partialRounds, 10)
+	appendBits(1<<30-1, 30)
+	for range 160 {
+		g.update()
+	}
+	return g
+}
+
+func (g *grain) update() byte {
+	bit := g.state[62] ^ g.state[51] ^ g.state[38] ^ g.state[23] ^ g.state[13] ^ g.state[0]
+	g.state = append(g.state[1:], bit)
+	return bit
+}
+
+// nextBit uses the self-shrinking mode: bits are taken by pairs and the second one is only output if the first one is set
+func (g *grain) nextBit() byte {
+	for {
+		if g.update() == 1 {
+			return g.update()
+		}
+		g.update()
+	}
+}
+
+func (g *grain) nextBits(n int) *big.Int {
+	value := new(big.Int)
+	for range n {
+		value.Lsh(value, 1)
+		if g.nextBit() == 1 {
+			value.SetBit(value, 0, 1)
+		}
+	}
+	return value
+}
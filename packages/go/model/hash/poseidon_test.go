@@ -0,0 +1,68 @@
+package hash_test
+
+import (
+	"math/big"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"gotest.tools/assert"
+)
+
+// TestPoseidon ...
+func TestPoseidon(t *testing.T) {
+	// Reference values from circomlibjs
+	found, err := hash.Poseidon(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, found.String(), "7853200120776062878684798364095072458815029376092732009249414926327459813530")
+
+	found, err = hash.Poseidon(big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, found.String(), "18821383157269793795438455681495246036402687001665670618754263018637548127333")
+
+	_, err = hash.Poseidon()
+	assert.Error(t, err, "invalid number of poseidon inputs")
+
+	_, err = hash.Poseidon(hash.BN254_SCALAR_FIELD)
+	assert.Error(t, err, "poseidon input not in field")
+
+	poseidon, err := hash.BuildFunction(hash.POSEIDON_BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000001"))
+	two := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000002"))
+	h := poseidon(append(append([]byte{}, one...), two...))
+	assert.Equal(t, utls.ToHex(h), "115cc0f5e7d690413df64c6b9662e9cf2a3617f2743245519e19607a4417189a")
+	assert.Assert(t, hash.IsCorrect(h, hash.POSEIDON_BN254))
+
+	// Not a field element
+	notInField := hash.BN254_SCALAR_FIELD.FillBytes(make([]byte, 32))
+	assert.Assert(t, poseidon(notInField) == nil)
+	assert.Assert(t, !hash.IsCorrect(notInField, hash.POSEIDON_BN254))
+	assert.Assert(t, poseidon([]byte("test")) == nil)
+	assert.Assert(t, poseidon(hash.PoseidonEncode([]byte("test"))) != nil)
+
+	_, err = hash.BuildKeyedFunction(hash.POSEIDON_BN254, []byte("secret"))
+	assert.Error(t, err, "invalid key: poseidon-bn254 does not support keyed hashing")
+}
+
+// TestPoseidonEncode ...
+func TestPoseidonEncode(t *testing.T) {
+	found := hash.PoseidonEncode([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), "0000000000000000000000000000000000000000000000000000000074657374")
+
+	data := make([]byte, 40)
+	for i := range data {
+		data[i] = 0xff
+	}
+	found = hash.PoseidonEncode(data)
+	assert.Equal(t, len(found), 64)
+	assert.Equal(t, found[0], byte(0))
+	assert.Equal(t, found[32+22], byte(0))
+	assert.Equal(t, found[32+23], byte(0xff))
+}
@@ -0,0 +1,143 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+	stdhash "hash"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	keccak "golang.org/x/crypto/sha3"
+)
+
+// Constructor instantiates a new hash for an engine
+type Constructor func() stdhash.Hash
+
+// KeyedConstructor instantiates a new hash for an engine natively supporting keyed hashing,
+// the passed key being empty for unkeyed hashing
+type KeyedConstructor func(key []byte) (stdhash.Hash, error)
+
+type engine struct {
+	digestSize int
+	regex      *regexp.Regexp
+	newHash    KeyedConstructor
+	isKeyed    bool
+	check      func(Hash) bool
+
+	multihashCode uint64
+	hasMultihash  bool
+}
+
+var (
+	registry   = map[string]*engine{}
+	registryMu sync.RWMutex
+
+	// nameRegex keeps names from breaking the dot-separated stringified proofs and their comma-separated flags
+	nameRegex = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+func init() {
+	_ = Register(SHA_256, sha256.Size, sha256.New)
+	_ = Register(SHA_384, sha512.Size384, sha512.New384)
+	_ = Register(SHA_512, sha512.Size, sha512.New)
+	_ = Register(SHA3_256, 32, func() stdhash.Hash { return sha3.New256() })
+	_ = Register(SHA3_512, 64, func() stdhash.Hash { return sha3.New512() })
+	_ = Register(KECCAK_256, 32, keccak.NewLegacyKeccak256)
+	_ = RegisterKeyed(BLAKE2B_256, blake2b.Size256, blake2b.New256)
+	_ = RegisterKeyed(BLAKE2B_512, blake2b.Size, blake2b.New512)
+	_ = RegisterKeyed(BLAKE2S_256, blake2s.Size, blake2s.New256)
+	_ = RegisterKeyed(BLAKE3, blake3Size, newBlake3)
+	_ = register(POSEIDON_BN254, poseidonSize, newPoseidon, true, isFieldElement)
+
+	_ = RegisterMultihashCode(SHA_256, MULTIHASH_SHA_256)
+	_ = RegisterMultihashCode(SHA_384, MULTIHASH_SHA_384)
+	_ = RegisterMultihashCode(SHA_512, MULTIHASH_SHA_512)
+	_ = RegisterMultihashCode(SHA3_256, MULTIHASH_SHA3_256)
+	_ = RegisterMultihashCode(SHA3_512, MULTIHASH_SHA3_512)
+	_ = RegisterMultihashCode(KECCAK_256, MULTIHASH_KECCAK_256)
+	_ = RegisterMultihashCode(BLAKE2B_256, MULTIHASH_BLAKE2B_256)
+	_ = RegisterMultihashCode(BLAKE2B_512, MULTIHASH_BLAKE2B_512)
+	_ = RegisterMultihashCode(BLAKE2S_256, MULTIHASH_BLAKE2S_256)
+	_ = RegisterMultihashCode(BLAKE3, MULTIHASH_BLAKE3)
+}
+
+// Register adds the passed engine to the list of available hashing engines, making it usable everywhere an engine name is expected
+// (trees, proofs, JSON representations, etc.), provided its name is made of lowercase letters, digits and hyphens and isn't already taken,
+// and its digests are of the passed size in bytes.
+func Register(name string, digestSize int, constructor Constructor) error {
+	if constructor == nil {
+		return exception.NewInvalidEngineError(fmt.Sprintf("%s has no constructor", name))
+	}
+	return register(name, digestSize, func(key []byte) (stdhash.Hash, error) {
+		return constructor(), nil
+	}, false, nil)
+}
+
+// RegisterKeyed adds the passed engine natively supporting keyed hashing to the list of available hashing engines
+func RegisterKeyed(name string, digestSize int, constructor KeyedConstructor) error {
+	if constructor == nil {
+		return exception.NewInvalidEngineError(fmt.Sprintf("%s has no constructor", name))
+	}
+	return register(name, digestSize, constructor, true, nil)
+}
+
+// Engines returns the sorted names of all registered engines
+func Engines() (names []string) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+//--- utility
+
+func register(name string, digestSize int, constructor KeyedConstructor, isKeyed bool, check func(Hash) bool) error {
+	if name == "" || name == MULTIHASH {
+		return exception.NewInvalidEngineError(fmt.Sprintf("reserved name: %q", name))
+	}
+	if !nameRegex.MatchString(name) {
+		return exception.NewInvalidEngineError(fmt.Sprintf("invalid name: %q", name))
+	}
+	if digestSize <= 0 {
+		return exception.NewInvalidEngineError(fmt.Sprintf("%s has an invalid digest size: %d", name, digestSize))
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		return exception.NewInvalidEngineError(fmt.Sprintf("%s already registered", name))
+	}
+	registry[name] = &engine{
+		digestSize: digestSize,
+		regex:      regexp.MustCompile(fmt.Sprintf(`^[a-f0-9]{%d}$`, digestSize*2)),
+		newHash:    constructor,
+		isKeyed:    isKeyed,
+		check:      check,
+	}
+	return nil
+}
+
+// unregister removes the passed engine from the registry, for tests only
+func unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
+
+func lookup(name string) (eng *engine, err error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	eng, found := registry[name]
+	if !found {
+		err = exception.NewInvalidEngineError(name)
+	}
+	return
+}
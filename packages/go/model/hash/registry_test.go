@@ -0,0 +1,71 @@
+package hash_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"gotest.tools/assert"
+)
+
+// TestRegister ...
+func TestRegister(t *testing.T) {
+	err := hash.Register("test-sha-224", sha256.Size224, sha256.New224)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { hash.Unregister("test-sha-224") })
+	sha224, err := hash.BuildFunction("test-sha-224")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := sha224([]byte("test"))
+	assert.Equal(t, utls.ToHex(found), "90a3ed9e32b2aaf4c61c410eb925426119e1a9dc53d4286ade99a809")
+	assert.Assert(t, hash.IsCorrect(found, "test-sha-224"))
+	assert.Assert(t, !hash.IsCorrect(found, hash.SHA_256))
+	size, err := hash.GetDigestSize("test-sha-224")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, size, 28)
+	assert.Assert(t, contains(hash.Engines(), "test-sha-224"))
+	assert.Assert(t, contains(hash.Engines(), hash.SHA_256))
+
+	hmacSha224, err := hash.BuildKeyedFunction("test-sha-224", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, hash.IsCorrect(hmacSha224([]byte("test")), "test-sha-224"))
+
+	err = hash.Register(hash.SHA_256, sha256.Size, sha256.New)
+	assert.Error(t, err, "invalid engine: sha-256 already registered")
+
+	err = hash.Register("test-empty", 0, sha256.New)
+	assert.Error(t, err, "invalid engine: test-empty has an invalid digest size: 0")
+
+	err = hash.Register("test-nil", 32, nil)
+	assert.Error(t, err, "invalid engine: test-nil has no constructor")
+
+	err = hash.Register("", 32, sha256.New)
+	assert.Error(t, err, `invalid engine: reserved name: ""`)
+
+	err = hash.Register(hash.MULTIHASH, 32, sha256.New)
+	assert.Error(t, err, `invalid engine: reserved name: "multihash"`)
+
+	// Names may not break the format of stringified proofs
+	for _, name := range []string{"sha.224", "sha,224", "SHA-224", "sha 224", "sha=224"} {
+		err = hash.Register(name, 28, sha256.New224)
+		assert.Error(t, err, fmt.Sprintf("invalid engine: invalid name: %q", name))
+	}
+}
+
+func contains(list []string, item string) bool {
+	for _, l := range list {
+		if l == item {
+			return true
+		}
+	}
+	return false
+}
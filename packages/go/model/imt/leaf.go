@@ -0,0 +1,41 @@
+package imt
+
+import (
+	"math/big"
+)
+
+// WORD_SIZE is the size in bytes of each of the three big-endian words a leaf is hashed from
+const WORD_SIZE = 32
+
+// Leaf is an item of the sorted linked list of the values of an indexed Merkle tree, pointing to the leaf holding the next higher value.
+// The leaf holding the highest value points to index zero and value zero, ie. the initial leaf.
+type Leaf struct {
+	Value     *big.Int
+	NextIndex int
+	NextValue *big.Int
+}
+
+// Bytes returns the concatenation of the three 32-byte big-endian words the leaf hash is computed from,
+// ie. the three field elements to pass to Poseidon with the "poseidon-bn254" engine
+func (l *Leaf) Bytes() []byte {
+	b := make([]byte, 3*WORD_SIZE)
+	l.Value.FillBytes(b[:WORD_SIZE])
+	big.NewInt(int64(l.NextIndex)).FillBytes(b[WORD_SIZE : 2*WORD_SIZE])
+	l.NextValue.FillBytes(b[2*WORD_SIZE:])
+	return b
+}
+
+// IsLowLeafOf tells whether the leaf proves the absence of the passed value, ie. its value is lower
+// and either the next one is higher or it's the last leaf of the list
+func (l *Leaf) IsLowLeafOf(value *big.Int) bool {
+	return l.Value.Cmp(value) < 0 && (l.NextValue.Cmp(value) > 0 || (l.NextIndex == 0 && l.NextValue.Sign() == 0))
+}
+
+//--- utility
+
+// isWellFormed tells whether the values and the next index of the leaf fit in their 32-byte words
+func (l *Leaf) isWellFormed() bool {
+	return l.Value != nil && l.NextValue != nil && l.NextIndex >= 0 &&
+		l.Value.Sign() >= 0 && l.NextValue.Sign() >= 0 && l.Value.BitLen() <= 8*WORD_SIZE && l.NextValue.BitLen() <= 8*WORD_SIZE &&
+		big.NewInt(int64(l.NextIndex)).BitLen() <= 8*WORD_SIZE
+}
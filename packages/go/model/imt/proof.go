@@ -0,0 +1,76 @@
+package imt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// Proof is the inclusion proof of a leaf of an indexed Merkle tree, made of its pre-image, its index and all its siblings bottom-up.
+// When the leaf is the low leaf of a value, it is the non-membership proof of this value.
+type Proof struct {
+	Leaf     *Leaf
+	Index    int
+	Siblings hash.Hashes
+	Engine   string
+}
+
+// String returns the base64-encoded dot-separated concatenation of the hexadecimal value, the next index, the hexadecimal next value,
+// the index, the hexadecimal siblings and the engine, eg. Base64Encode("2a.3.3039.1.<sibling1><sibling2>.sha-256")
+func (p *Proof) String() string {
+	var siblings []string
+	for _, s := range p.Siblings {
+		siblings = append(siblings, utls.ToHex(s))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s.%d.%s.%d.%s.%s",
+		p.Leaf.Value.Text(16), p.Leaf.NextIndex, p.Leaf.NextValue.Text(16), p.Index, strings.Join(siblings, ""), p.Engine)))
+}
+
+// ProofFrom builds an indexed Merkle tree proof from the passed string, provided it's an actual stringified proof
+func ProofFrom(b64 string) (p *Proof, err error) {
+	str, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	parts := strings.Split(string(str), ".")
+	if len(parts) != 6 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	digestSize, e1 := hash.GetDigestSize(parts[5])
+	value, ok1 := new(big.Int).SetString(parts[0], 16)
+	nextIndex, e2 := strconv.Atoi(parts[1])
+	nextValue, ok2 := new(big.Int).SetString(parts[2], 16)
+	index, e3 := strconv.Atoi(parts[3])
+	siblings, e4 := utls.FromHex(parts[4])
+	if e1 != nil || e2 != nil || e3 != nil || e4 != nil || !ok1 || !ok2 || index < 0 || len(siblings)%digestSize != 0 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	leaf := &Leaf{
+		Value:     value,
+		NextIndex: nextIndex,
+		NextValue: nextValue,
+	}
+	if !leaf.isWellFormed() {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	p = &Proof{
+		Leaf:     leaf,
+		Index:    index,
+		Siblings: hash.Hashes{},
+		Engine:   parts[5],
+	}
+	for i := 0; i < len(siblings); i += digestSize {
+		p.Siblings = append(p.Siblings, siblings[i:i+digestSize])
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package imt_test
+
+import (
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/imt"
+	"gotest.tools/assert"
+)
+
+// TestProof ...
+func TestProof(t *testing.T) {
+	tree, err := imt.NewTree(16, hash.KECCAK_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []int64{100, 300, 200, 500} {
+		if _, err = tree.Insert(big.NewInt(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rootHash := tree.GetRootHash()
+
+	// Membership
+	proof, err := tree.GetProof(big.NewInt(300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(proof.Siblings), 16)
+	assert.Assert(t, tree.ValidateProof(proof, big.NewInt(300), rootHash))
+	assert.Assert(t, !tree.ValidateProof(proof, big.NewInt(200), rootHash))
+	instance, err := imt.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.String(), proof.String())
+	assert.Assert(t, tree.ValidateProof(instance, big.NewInt(300), rootHash))
+	_, err = tree.GetProof(big.NewInt(400))
+	assert.Error(t, err, "value not found: 400")
+
+	// Non-membership through the low leaf
+	proof, err = tree.GetNonMembershipProof(big.NewInt(400))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof.Leaf.Value.Int64(), int64(300))
+	assert.Equal(t, proof.Leaf.NextValue.Int64(), int64(500))
+	assert.Assert(t, tree.ValidateNonMembershipProof(proof, big.NewInt(400), rootHash))
+	assert.Assert(t, !tree.ValidateNonMembershipProof(proof, big.NewInt(500), rootHash))
+	assert.Assert(t, !tree.ValidateNonMembershipProof(proof, big.NewInt(250), rootHash))
+	_, err = tree.GetNonMembershipProof(big.NewInt(500))
+	assert.Error(t, err, "value already exists: 500")
+
+	// Beyond the highest value, the low leaf is the last one of the list
+	proof, _ = tree.GetNonMembershipProof(big.NewInt(1000))
+	assert.Equal(t, proof.Leaf.Value.Int64(), int64(500))
+	assert.Assert(t, tree.ValidateNonMembershipProof(proof, big.NewInt(1000), rootHash))
+
+	// Inserting updates the low leaf, so that the former proof is outdated
+	proof, _ = tree.GetNonMembershipProof(big.NewInt(400))
+	if _, err = tree.Insert(big.NewInt(400)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, !tree.ValidateNonMembershipProof(proof, big.NewInt(400), tree.GetRootHash()))
+	proof, _ = tree.GetProof(big.NewInt(300))
+	assert.Equal(t, proof.Leaf.NextValue.Int64(), int64(400))
+	assert.Assert(t, tree.ValidateProof(proof, big.NewInt(300), tree.GetRootHash()))
+
+	// Values wider than 256 bits are rejected instead of overflowing their word
+	tooWide := new(big.Int).Lsh(big.NewInt(1), 256)
+	proof.Leaf.Value = tooWide
+	assert.Assert(t, !tree.ValidateProof(proof, tooWide, tree.GetRootHash()))
+	proof.Leaf.Value, proof.Leaf.NextValue = big.NewInt(300), tooWide
+	assert.Assert(t, !tree.ValidateNonMembershipProof(proof, big.NewInt(301), tree.GetRootHash()))
+	_, err = imt.ProofFrom(base64.StdEncoding.EncodeToString([]byte("1" + strings.Repeat("0", 64) + ".0.0.1..sha-256")))
+	assert.ErrorContains(t, err, "invalid proof")
+}
@@ -0,0 +1,272 @@
+package imt
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// MAX_DEPTH is the maximum depth of an indexed Merkle tree
+const MAX_DEPTH = 32
+
+//--- TYPES
+
+// Tree is an indexed Merkle tree, ie. a fixed-depth Merkle tree whose leaves form a linked list of values sorted in ascending order,
+// as used for nullifier sets: the absence of a value is proven by the inclusion of its "low leaf", ie. the leaf holding the highest lower value,
+// which points to a higher one.
+// The first leaf is always the zero value and empty leaves are zero digests.
+type Tree struct {
+	engine       string
+	hashFunction hash.Function
+	depth        int
+	zeros        hash.Hashes
+	levels       []hash.Hashes
+	leaves       []*Leaf
+	sorted       []int
+}
+
+//--- METHODS
+
+// Depth returns the number of levels of the tree excluding the root
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// GetEngine returns the name of the used hashing function
+func (t *Tree) GetEngine() string {
+	return t.engine
+}
+
+// GetLeaf returns a copy of the leaf at the passed index
+func (t *Tree) GetLeaf(index int) (leaf *Leaf, err error) {
+	if index < 0 || index >= len(t.leaves) {
+		err = fmt.Errorf("index out of range: %d", index)
+		return
+	}
+	l := t.leaves[index]
+	leaf = &Leaf{
+		Value:     new(big.Int).Set(l.Value),
+		NextIndex: l.NextIndex,
+		NextValue: new(big.Int).Set(l.NextValue),
+	}
+	return
+}
+
+// GetNonMembershipProof returns the inclusion proof of the low leaf of the passed value, proving the value isn't in the tree.
+// NB: it is also the witness of the low leaf to update when inserting the value.
+func (t *Tree) GetNonMembershipProof(value *big.Int) (p *Proof, err error) {
+	if err = t.checkValue(value); err != nil {
+		return
+	}
+	position := t.search(value)
+	if position < len(t.sorted) && t.leaves[t.sorted[position]].Value.Cmp(value) == 0 {
+		err = fmt.Errorf("value already exists: %s", value)
+		return
+	}
+	p, err = t.proofAt(t.sorted[position-1])
+	return
+}
+
+// GetProof returns the inclusion proof of the leaf holding the passed value
+func (t *Tree) GetProof(value *big.Int) (p *Proof, err error) {
+	index, found := t.IndexOf(value)
+	if !found {
+		err = fmt.Errorf("value not found: %s", value)
+		return
+	}
+	p, err = t.proofAt(index)
+	return
+}
+
+// GetRootHash returns the hexadecimal representation of the root hash of the tree
+func (t *Tree) GetRootHash() string {
+	return utls.ToHex(t.node(t.depth, 0))
+}
+
+// IndexOf returns the index of the leaf holding the passed value, if any
+func (t *Tree) IndexOf(value *big.Int) (index int, found bool) {
+	if value == nil {
+		return
+	}
+	position := t.search(value)
+	if position < len(t.sorted) && t.leaves[t.sorted[position]].Value.Cmp(value) == 0 {
+		return t.sorted[position], true
+	}
+	return
+}
+
+// Insert adds the passed value at the next free index and returns it, the low leaf of the value being updated to point to the new leaf
+// which points to the former next value of the low leaf
+func (t *Tree) Insert(value *big.Int) (index int, err error) {
+	if err = t.checkValue(value); err != nil {
+		return
+	}
+	position := t.search(value)
+	if position < len(t.sorted) && t.leaves[t.sorted[position]].Value.Cmp(value) == 0 {
+		err = fmt.Errorf("value already exists: %s", value)
+		return
+	}
+	if len(t.leaves) == 1<<t.depth {
+		err = fmt.Errorf("tree is full")
+		return
+	}
+	index = len(t.leaves)
+	lowIndex := t.sorted[position-1]
+	low := t.leaves[lowIndex]
+	leaf := &Leaf{
+		Value:     new(big.Int).Set(value),
+		NextIndex: low.NextIndex,
+		NextValue: low.NextValue,
+	}
+	low.NextIndex, low.NextValue = index, leaf.Value
+	t.leaves = append(t.leaves, leaf)
+	t.sorted = append(t.sorted, 0)
+	copy(t.sorted[position+1:], t.sorted[position:])
+	t.sorted[position] = index
+	t.update(lowIndex)
+	t.update(index)
+	return
+}
+
+// Size returns the number of leaves, including the initial zero leaf
+func (t *Tree) Size() int {
+	return len(t.leaves)
+}
+
+// ValidateNonMembershipProof checks that the passed proof of a low leaf matches the passed root hash and proves the absence of the passed value
+func (t *Tree) ValidateNonMembershipProof(p *Proof, value *big.Int, rootHash string) bool {
+	return value != nil && p != nil && p.Leaf != nil && p.Leaf.IsLowLeafOf(value) && t.validate(p, rootHash)
+}
+
+// ValidateProof checks that the passed proof of the leaf holding the passed value matches the passed root hash
+func (t *Tree) ValidateProof(p *Proof, value *big.Int, rootHash string) bool {
+	return value != nil && p != nil && p.Leaf != nil && p.Leaf.Value.Cmp(value) == 0 && t.validate(p, rootHash)
+}
+
+// For internal use only
+
+// checkValue makes sure the passed value is a strictly positive 256-bit word making a correct leaf hash for the engine,
+// eg. a field element for Poseidon
+func (t *Tree) checkValue(value *big.Int) error {
+	if value == nil || value.Sign() <= 0 || value.BitLen() > 8*WORD_SIZE ||
+		!hash.IsCorrect(t.hashLeaf(&Leaf{Value: value, NextValue: value}), t.engine) {
+		return fmt.Errorf("invalid value: %v", value)
+	}
+	return nil
+}
+
+func (t *Tree) hashLeaf(leaf *Leaf) hash.Hash {
+	return t.hashFunction(leaf.Bytes())
+}
+
+func (t *Tree) hashNode(left, right hash.Hash) hash.Hash {
+	return t.hashFunction(append(append([]byte{}, left...), right...))
+}
+
+// node returns the node at the passed height and index, or the zero hash of the height if it's empty
+func (t *Tree) node(height, index int) hash.Hash {
+	if index < len(t.levels[height]) {
+		return t.levels[height][index]
+	}
+	return t.zeros[height]
+}
+
+func (t *Tree) proofAt(index int) (p *Proof, err error) {
+	leaf, err := t.GetLeaf(index)
+	if err != nil {
+		return
+	}
+	siblings := hash.Hashes{}
+	for height := 0; height < t.depth; height++ {
+		siblings = append(siblings, t.node(height, (index>>height)^1))
+	}
+	p = &Proof{
+		Leaf:     leaf,
+		Index:    index,
+		Siblings: siblings,
+		Engine:   t.engine,
+	}
+	return
+}
+
+// search returns the position in the sorted list of indices of the first leaf whose value isn't lower than the passed one
+func (t *Tree) search(value *big.Int) int {
+	return sort.Search(len(t.sorted), func(i int) bool {
+		return t.leaves[t.sorted[i]].Value.Cmp(value) >= 0
+	})
+}
+
+// update recomputes the hash of the leaf at the passed index and all its ancestors
+func (t *Tree) update(index int) {
+	h := t.hashLeaf(t.leaves[index])
+	for height := 0; ; height++ {
+		i := index >> height
+		if i == len(t.levels[height]) {
+			t.levels[height] = append(t.levels[height], h)
+		} else {
+			t.levels[height][i] = h
+		}
+		if height == t.depth {
+			return
+		}
+		if i%2 == 0 {
+			h = t.hashNode(h, t.node(height, i+1))
+		} else {
+			h = t.hashNode(t.node(height, i-1), h)
+		}
+	}
+}
+
+func (t *Tree) validate(p *Proof, rootHash string) bool {
+	if p.Engine != t.engine || p.Index < 0 || p.Index >= 1<<t.depth || len(p.Siblings) != t.depth || !p.Leaf.isWellFormed() {
+		return false
+	}
+	h := t.hashLeaf(p.Leaf)
+	for height, sibling := range p.Siblings {
+		if (p.Index>>height)%2 == 0 {
+			h = t.hashNode(h, sibling)
+		} else {
+			h = t.hashNode(sibling, h)
+		}
+	}
+	return utls.ToHex(h) == rootHash
+}
+
+//--- FUNCTIONS
+
+// NewTree instantiates a new indexed Merkle tree of the passed depth using the passed engine (SHA-256 by default), only holding the zero leaf
+func NewTree(depth int, engine ...string) (t *Tree, err error) {
+	if depth < 1 || depth > MAX_DEPTH {
+		err = fmt.Errorf("invalid depth: %d", depth)
+		return
+	}
+	usingEngine := hash.SHA_256
+	if len(engine) == 1 && engine[0] != "" {
+		usingEngine = engine[0]
+	}
+	fn, err := hash.BuildFunction(usingEngine)
+	if err != nil {
+		return
+	}
+	digestSize, err := hash.GetDigestSize(usingEngine)
+	if err != nil {
+		return
+	}
+	t = &Tree{
+		engine:       usingEngine,
+		hashFunction: fn,
+		depth:        depth,
+		zeros:        hash.Hashes{make(hash.Hash, digestSize)},
+		levels:       make([]hash.Hashes, depth+1),
+		leaves:       []*Leaf{{Value: big.NewInt(0), NextValue: big.NewInt(0)}},
+		sorted:       []int{0},
+	}
+	for height := 1; height <= depth; height++ {
+		t.zeros = append(t.zeros, t.hashNode(t.zeros[height-1], t.zeros[height-1]))
+	}
+	t.update(0)
+	return
+}
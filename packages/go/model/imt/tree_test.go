@@ -0,0 +1,101 @@
+package imt_test
+
+import (
+	"math/big"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/imt"
+	"gotest.tools/assert"
+)
+
+var sha256, _ = hash.BuildFunction(hash.SHA_256)
+
+func concat(left, right []byte) []byte {
+	return append(append([]byte{}, left...), right...)
+}
+
+// TestIndexedMerkleTree ...
+func TestIndexedMerkleTree(t *testing.T) {
+	_, err := imt.NewTree(0)
+	assert.Error(t, err, "invalid depth: 0")
+
+	tree, err := imt.NewTree(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree.Depth(), 2)
+	assert.Equal(t, tree.GetEngine(), hash.SHA_256)
+	assert.Equal(t, tree.Size(), 1)
+
+	for _, value := range []int64{30, 10, 20} {
+		if _, err = tree.Insert(big.NewInt(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = tree.Insert(big.NewInt(20))
+	assert.Error(t, err, "value already exists: 20")
+	_, err = tree.Insert(big.NewInt(40))
+	assert.Error(t, err, "tree is full")
+	_, err = tree.Insert(big.NewInt(0))
+	assert.Error(t, err, "invalid value: 0")
+
+	// The leaves form a sorted linked list: 0 -> 10 -> 20 -> 30 -> 0
+	expected := []*imt.Leaf{
+		{Value: big.NewInt(0), NextIndex: 2, NextValue: big.NewInt(10)},
+		{Value: big.NewInt(30), NextIndex: 0, NextValue: big.NewInt(0)},
+		{Value: big.NewInt(10), NextIndex: 3, NextValue: big.NewInt(20)},
+		{Value: big.NewInt(20), NextIndex: 1, NextValue: big.NewInt(30)},
+	}
+	hashes := [][]byte{}
+	for i, leaf := range expected {
+		actual, err := tree.GetLeaf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, actual.Value.Cmp(leaf.Value), 0)
+		assert.Equal(t, actual.NextIndex, leaf.NextIndex)
+		assert.Equal(t, actual.NextValue.Cmp(leaf.NextValue), 0)
+		hashes = append(hashes, sha256(leaf.Bytes()))
+	}
+	assert.Equal(t, tree.GetRootHash(), utls.ToHex(sha256(concat(sha256(concat(hashes[0], hashes[1])), sha256(concat(hashes[2], hashes[3]))))))
+
+	index, found := tree.IndexOf(big.NewInt(20))
+	assert.Assert(t, found)
+	assert.Equal(t, index, 3)
+	_, found = tree.IndexOf(big.NewInt(25))
+	assert.Assert(t, !found)
+
+	// Empty leaves are zero digests
+	other, _ := imt.NewTree(2)
+	zero := make([]byte, 32)
+	initial := sha256((&imt.Leaf{Value: big.NewInt(0), NextValue: big.NewInt(0)}).Bytes())
+	assert.Equal(t, other.GetRootHash(), utls.ToHex(sha256(concat(sha256(concat(initial, zero)), sha256(concat(zero, zero))))))
+}
+
+// TestPoseidonIndexedMerkleTree ...
+func TestPoseidonIndexedMerkleTree(t *testing.T) {
+	tree, err := imt.NewTree(8, hash.POSEIDON_BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.Insert(hash.BN254_SCALAR_FIELD)
+	assert.ErrorContains(t, err, "invalid value")
+	if _, err = tree.Insert(big.NewInt(42)); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := tree.GetProof(big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The leaf hash is Poseidon(value, nextIndex, nextValue) as in circuits
+	poseidon, _ := hash.BuildFunction(hash.POSEIDON_BN254)
+	expected, err := hash.Poseidon(big.NewInt(42), big.NewInt(0), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, new(big.Int).SetBytes(poseidon(proof.Leaf.Bytes())).Cmp(expected), 0)
+	assert.Assert(t, tree.ValidateProof(proof, big.NewInt(42), tree.GetRootHash()))
+}
@@ -0,0 +1,213 @@
+package merkle
+
+import (
+	"fmt"
+
+	"github.com/cyrildever/go-utls/common/packer"
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+//--- TYPES
+
+// Frontier is a compact range of a Merkle tree, ie. the nodes still waiting for their siblings at each level, to compute the root hash
+// of as many leaves as needed without keeping them in memory: it holds at most `arity - 1` hashes per level, eg. log2(n) hashes for n leaves
+// in a binary tree, and gives the same root hash as a `Tree` built with the same options and leaves.
+//
+// NB: The leaves of a sorted tree can't be streamed. In a salted tree, the salts are still kept in the salt store.
+type Frontier struct {
+	tree  *Tree
+	nodes []hash.Hashes
+	size  int
+}
+
+//--- METHODS
+
+// Append adds either sources (by passing `true` to the first parameter) or hashes after the existing leaves, hashing any completed node
+func (f *Frontier) Append(doHash bool, data ...[]byte) error {
+	leaves, err := f.tree.toLeaves(doHash, data)
+	if err != nil {
+		return err
+	}
+	if len(leaves) == 0 {
+		return fmt.Errorf("empty tree")
+	}
+	for _, leaf := range leaves {
+		node := leaf
+		for level := 0; ; level++ {
+			if level == len(f.nodes) {
+				f.nodes = append(f.nodes, hash.Hashes{})
+			}
+			f.nodes[level] = append(f.nodes[level], node)
+			if len(f.nodes[level]) < f.tree.arity() {
+				break
+			}
+			node = f.tree.hashNode(f.nodes[level]...)
+			f.nodes[level] = hash.Hashes{}
+		}
+		f.size++
+	}
+	return nil
+}
+
+// GetEngine returns the name of the used hashing function
+func (f *Frontier) GetEngine() string {
+	return f.tree.GetEngine()
+}
+
+// GetRootHash returns the hexadecimal representation of the root hash of the leaves appended so far,
+// ie. of its multihash if the tree uses the multihash representation
+func (f *Frontier) GetRootHash() (rootHash string, err error) {
+	if f.size == 0 {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	rootHash = f.tree.encode(f.root())
+	return
+}
+
+// JSON returns the JSON-stringified representation of the current frontier, eg. to checkpoint it (see `FrontierFrom()`)
+func (f *Frontier) JSON() (json string, err error) {
+	opts, err := packer.JSONMarshal(f.tree.options)
+	if err != nil {
+		return
+	}
+	nodesHex := [][]string{}
+	for _, level := range f.nodes {
+		hexes := []string{}
+		for _, node := range level {
+			hexes = append(hexes, utls.ToHex(node))
+		}
+		nodesHex = append(nodesHex, hexes)
+	}
+	nodes, err := packer.JSONMarshal(nodesHex)
+	if err != nil {
+		return
+	}
+	json = fmt.Sprintf(`{"options":%s,"size":%d,"nodes":%s}`, opts, f.size, nodes)
+	return
+}
+
+// Size returns the number of leaves appended so far
+func (f *Frontier) Size() int {
+	return f.size
+}
+
+// For internal use only
+
+// root folds the waiting nodes bottom-up, the node left over from the lower levels being the last child at each level
+// and the unpaired node of a level being handled according to the odd-node policy, as in `Tree.build()`
+func (f *Frontier) root() (carry hash.Hash) {
+	for level := range f.nodes {
+		nodes := append(hash.Hashes{}, f.nodes[level]...)
+		if carry != nil {
+			nodes = append(nodes, carry)
+		}
+		switch {
+		case len(nodes) == 0:
+		case len(nodes) > 1:
+			carry = f.tree.hashNode(nodes...)
+		case !f.hasNodesAbove(level):
+			return nodes[0]
+		case f.tree.options.OddNodePolicy == ODD_NODE_DUPLICATE:
+			carry = f.tree.hashNode(nodes[0], nodes[0])
+		default:
+			carry = nodes[0]
+		}
+	}
+	return
+}
+
+func (f *Frontier) hasNodesAbove(level int) bool {
+	for _, nodes := range f.nodes[level+1:] {
+		if len(nodes) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+//--- FUNCTIONS
+
+// NewFrontier instantiates a new empty frontier for a Merkle tree of the passed options
+func NewFrontier(options ...*TreeOptions) (f *Frontier, err error) {
+	tree, err := NewTree(options...)
+	if err != nil {
+		return
+	}
+	if tree.options.Sort {
+		err = fmt.Errorf("unable to stream a sorted tree")
+		return
+	}
+	f = &Frontier{
+		tree:  tree,
+		nodes: []hash.Hashes{},
+	}
+	return
+}
+
+type decodedFrontierJSON struct {
+	Options *TreeOptions `json:"options"`
+	Size    int          `json:"size"`
+	Nodes   [][]string   `json:"nodes"`
+}
+
+// FrontierFrom builds a `Frontier` instance from the passed string, using the passed key if it was a keyed tree,
+// provided its nodes match its size and no key is passed for an unkeyed tree
+func FrontierFrom(json string, key ...[]byte) (f *Frontier, err error) {
+	var decoded decodedFrontierJSON
+	if err = packer.JSONUnmarshal([]byte(json), &decoded); err != nil {
+		return
+	}
+	opts := DEFAULT_TREE_OPTIONS
+	if decoded.Options != nil {
+		opts = decoded.Options
+	}
+	if len(key) == 1 && len(key[0]) != 0 {
+		if !opts.Keyed {
+			err = exception.NewInvalidKeyError("unkeyed tree")
+			return
+		}
+		keyed := *opts
+		keyed.Key = key[0]
+		opts = &keyed
+	} else if opts.Keyed {
+		msg := "missing key"
+		if opts.KeyID != "" {
+			msg = fmt.Sprintf("missing key %s", opts.KeyID)
+		}
+		err = exception.NewInvalidKeyError(msg)
+		return
+	}
+	frontier, err := NewFrontier(opts)
+	if err != nil {
+		return
+	}
+	// The number of nodes waiting at each level is the matching digit of the size in base arity
+	size := decoded.Size
+	for _, level := range decoded.Nodes {
+		if len(level) != size%frontier.tree.arity() {
+			err = exception.NewInvalidJSONError(fmt.Sprintf("invalid frontier of size %d", decoded.Size))
+			return
+		}
+		size /= frontier.tree.arity()
+		nodes := hash.Hashes{}
+		for _, node := range level {
+			h, e := utls.FromHex(node)
+			if e != nil || !hash.IsCorrect(h, frontier.GetEngine(), opts.TruncatedSize) {
+				err = exception.NewInvalidJSONError(fmt.Sprintf("invalid node: %s", node))
+				return
+			}
+			nodes = append(nodes, h)
+		}
+		frontier.nodes = append(frontier.nodes, nodes)
+	}
+	if size != 0 || decoded.Size < 0 {
+		err = exception.NewInvalidJSONError(fmt.Sprintf("invalid frontier of size %d", decoded.Size))
+		return
+	}
+	frontier.size = decoded.Size
+	f = frontier
+	return
+}
@@ -0,0 +1,94 @@
+package merkle_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/merkle"
+	"gotest.tools/assert"
+)
+
+// TestFrontier ...
+func TestFrontier(t *testing.T) {
+	options := []*merkle.TreeOptions{
+		merkle.DEFAULT_TREE_OPTIONS,
+		{Engine: hash.SHA_256, OddNodePolicy: merkle.ODD_NODE_DUPLICATE},
+		{Engine: hash.SHA_256, DomainSeparation: true, OddNodePolicy: merkle.ODD_NODE_SPLIT},
+		{Engine: hash.KECCAK_256, SortedPairs: true},
+		{Engine: hash.BLAKE2B_256, Arity: 3},
+		{Engine: hash.SHA_512, DoubleHash: true, TruncatedSize: 20},
+		{Engine: hash.SHA_256, Multihash: true},
+		{Engine: hash.SHA_256, Key: []byte("secret")},
+	}
+	for _, opts := range options {
+		frontier, err := merkle.NewFrontier(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := [][]byte{}
+		for i := 0; i < 40; i++ {
+			data = append(data, []byte(fmt.Sprintf("leaf%d", i)))
+			if err = frontier.Append(true, data[i]); err != nil {
+				t.Fatal(err)
+			}
+			tree, _ := merkle.NewTree(opts)
+			_, _ = tree.AddLeaves(true, data...)
+			expected, _ := tree.GetRootHash()
+			rootHash, err := frontier.GetRootHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, rootHash, expected, fmt.Sprintf("%+v with %d leaves", opts, i+1))
+		}
+		assert.Equal(t, frontier.Size(), 40)
+	}
+
+	// At most log2(n) hashes
+	frontier, _ := merkle.NewFrontier()
+	_, err := frontier.GetRootHash()
+	assert.Error(t, err, "tree not built")
+	for i := 0; i < 1000; i++ {
+		_ = frontier.Append(false, sha256([]byte{byte(i), byte(i >> 8)}))
+	}
+	json, _ := frontier.JSON()
+	assert.Equal(t, len(regexp.MustCompile(`"[0-9a-f]{64}"`).FindAllString(json, -1)), 6) // 1000 = 0b1111101000
+
+	_, err = merkle.NewFrontier(&merkle.TreeOptions{Engine: hash.SHA_256, Sort: true})
+	assert.Error(t, err, "unable to stream a sorted tree")
+}
+
+// TestFrontierFrom ...
+func TestFrontierFrom(t *testing.T) {
+	opts := &merkle.TreeOptions{Engine: hash.SHA_256, Arity: 4, Key: []byte("secret"), KeyID: "key-1"}
+	frontier, _ := merkle.NewFrontier(opts)
+	for i := 0; i < 27; i++ {
+		_ = frontier.Append(true, []byte{byte(i)})
+	}
+	json, err := frontier.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, !strings.Contains(json, "secret"))
+	_, err = merkle.FrontierFrom(json)
+	assert.Error(t, err, "invalid key: missing key key-1")
+	_, err = merkle.FrontierFrom(strings.Replace(json, `"keyed":true,`, "", 1), []byte("secret"))
+	assert.Error(t, err, "invalid key: unkeyed tree")
+
+	// Resuming from the checkpoint
+	restored, err := merkle.FrontierFrom(json, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, restored.Size(), 27)
+	_ = frontier.Append(true, []byte("next"))
+	_ = restored.Append(true, []byte("next"))
+	expected, _ := frontier.GetRootHash()
+	rootHash, _ := restored.GetRootHash()
+	assert.Equal(t, rootHash, expected)
+
+	_, err = merkle.FrontierFrom(strings.Replace(json, `"size":27`, `"size":26`, 1), []byte("secret"))
+	assert.Error(t, err, "invalid frontier of size 26")
+}
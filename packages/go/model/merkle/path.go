@@ -1,7 +1,7 @@
 package merkle
 
 import (
-	"math"
+	"fmt"
 
 	"github.com/cyrildever/merkle-trees/packages/go/exception"
 )
@@ -24,33 +24,28 @@ const (
 //
 // => Path = "10"
 //
+// Levels are walked bottom-up as in `Tree` where an unpaired node is promoted to the next level, such a level being skipped,
+// so that the path may be shorter than the depth, eg. "0" for the last leaf of a tree of 9 leaves.
+//
 // NB: Building with left = 1 and right = 0 allows to easily build the proof using the corresponding index for each level top-down
 func NewPath(index, size, depth int) (path Path, err error) {
-	initialDepth := depth
-	for size > 0 && depth > 0 {
-		half := halfBucket(float64(size))
-		if float64(index) < half {
-			path += LEFT
-			size = int(math.Max(half, 1))
-		} else {
-			path += RIGHT
-			index -= int(half)
-			if size-int(half)*2 == 0 {
-				size = int(math.Max(half, 1))
-			} else {
-				size -= int(half)
-			}
+	if index < 0 || index >= size {
+		err = exception.NewUnableToBuildPathError(fmt.Sprintf("index %d out of %d", index, size))
+		return
+	}
+	level := 0
+	for n := size; n > 1; n = (n + 1) / 2 {
+		if index%2 == 1 {
+			path = RIGHT + path
+		} else if index+1 < n {
+			path = LEFT + path
 		}
-		depth--
+		index /= 2
+		level++
 	}
-	if len(path) != initialDepth {
-		err = exception.NewUnableToBuildPathError(string(path))
+	if level != depth {
+		err = exception.NewUnableToBuildPathError(fmt.Sprintf("depth %d instead of %d", level, depth))
+		path = ""
 	}
 	return
 }
-
-//-- utility
-
-func halfBucket(from float64) float64 {
-	return math.Pow(2, math.Ceil(math.Log(from)/math.Log(2))) / 2
-}
@@ -48,10 +48,37 @@ func TestPath(t *testing.T) {
 	}
 	assert.Equal(t, found, expected)
 
-	expected = "0111"
+	// The last leaf is promoted up to the top level
+	expected = "0"
 	found, err = merkle.NewPath(8, size, 4)
 	if err != nil {
 		t.Fatal(err)
 	}
 	assert.Equal(t, found, expected)
+
+	_, err = merkle.NewPath(9, size, 4)
+	assert.Error(t, err, "unable to build path, found: index 9 out of 9")
+	_, err = merkle.NewPath(1, size, 3)
+	assert.Error(t, err, "unable to build path, found: depth 4 instead of 3")
+
+	// Same paths as the proofs of a tree
+	for size := 2; size <= 33; size++ {
+		data := [][]byte{}
+		for i := 0; i < size; i++ {
+			data = append(data, []byte{byte(i)})
+		}
+		tree, _ := merkle.NewTree()
+		proofs, err := tree.AddLeaves(true, data...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		depth, _ := tree.Depth()
+		for index, proof := range proofs {
+			path, err := merkle.NewPath(index, size, depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, path, proof.Path)
+		}
+	}
 }
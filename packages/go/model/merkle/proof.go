@@ -14,13 +14,38 @@ import (
 
 // Proof defines the Merkle tree proof that consists of the trail of intermediate hashes suffixed with the path,
 // the name of the hashing engine used and the size of the Merkle tree at the date of the proof.
+// If `Multihash` is `true`, the stringified proof holds self-describing multihashes instead of the name of the engine.
+// If `DomainSeparation` is `true`, the proof comes from a tree using RFC 6962 leaf and node prefixes (see `TreeOptions`).
+// In a salted tree, `Salt` is the salt of the proven leaf only, so that its owner may hash the source and check the proof independently.
+// In a truncated-digest tree, `TruncatedSize` is the length in bytes of all the hashes of the trail.
+// In a k-ary tree, `Arity` is the number of children per node, the trail holding all the siblings at each level and the path the hexadecimal position
+// of the node among them at each level.
+// If `SortedPairs` is `true`, the proof comes from a tree hashing sorted pairs and has no path.
+// If `Keyed` is `true`, the proof comes from a keyed tree and its leaf must be hashed with the same key.
 type Proof struct {
 	Trail hash.Hashes
 	Path
-	Size   int
-	Engine string
+	Size             int
+	Engine           string
+	Multihash        bool
+	DomainSeparation bool
+	Salt             []byte
+	TruncatedSize    int
+	Arity            int
+	SortedPairs      bool
+	Keyed            bool
 }
 
+// Flags of the optional last part of a stringified proof
+const (
+	DOMAIN_SEPARATION_FLAG = "rfc6962"
+	SALT_FLAG              = "salt"
+	TRUNCATED_FLAG         = "truncated"
+	ARITY_FLAG             = "arity"
+	SORTED_FLAG            = "sorted"
+	KEYED_FLAG             = "keyed"
+)
+
 // String returns the base64-encoded dot-separated concatenation of the hexadecimal hashes, the path, the engine and the size of the tree, eg.
 //
 //       (rootHash)
@@ -30,12 +55,21 @@ type Proof struct {
 //   (leaf)  hash1
 //
 //	=> proofStr := Base64Encode("<hash1><hash2>.11.sha-256.4")
+//
+// or with multihashes: Base64Encode("<multihash1><multihash2>.11.multihash.4")
+//
+// Any mode of the tree changing the way the proof is verified is added as a comma-separated list of flags, eg. Base64Encode("<hash1><hash2>.11.sha-256.4.rfc6962,salt=<salt>")
+//
+// Proofs of trees hashing sorted pairs have an empty path and the "sorted" flag, eg. Base64Encode("<hash1><hash2>..sha-256.4.sorted").
+//
+// A proof that can't be stringified, eg. a multihash proof whose engine has no multihash code, returns an empty string
+// that `ProofFrom()` rejects as an invalid proof.
 func (p *Proof) String() string {
-	var hashes []string
-	for _, h := range p.Trail {
-		hashes = append(hashes, utls.ToHex(h))
+	str, err := p.toString()
+	if err != nil {
+		return ""
 	}
-	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s.%s.%s.%d", strings.Join(hashes, ""), p.Path, p.Engine, p.Size)))
+	return str
 }
 
 // NewProof ...
@@ -60,7 +94,7 @@ func ProofFrom(b64 string) (p *Proof, err error) {
 		return
 	}
 	parts := strings.Split(string(str), ".")
-	if len(parts) != 4 {
+	if len(parts) != 4 && len(parts) != 5 {
 		err = exception.NewInvalidMerkleProofError(b64)
 		return
 	}
@@ -73,25 +107,183 @@ func ProofFrom(b64 string) (p *Proof, err error) {
 		}
 		return
 	}
-	switch engine {
-	case hash.SHA_256:
-		hashes := regexp.MustCompile("(.{64})").FindAllString(parts[0], -1)
-		if hashes == nil {
+	flagged := &Proof{}
+	if len(parts) == 5 {
+		if err = flagged.setFlags(b64, parts[4]); err != nil {
+			return
+		}
+	}
+	if flagged.SortedPairs && path != "" {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	if engine == hash.MULTIHASH {
+		if flagged.TruncatedSize != 0 {
 			err = exception.NewInvalidMerkleProofError(b64)
 			return
 		}
-		var trail hash.Hashes
-		for _, h := range hashes {
-			bytes, e := utls.FromHex(h)
-			if e != nil {
-				err = exception.NewInvalidMerkleProofError(b64)
-				return
+		p, err = multihashProofFrom(b64, parts[0], path, size)
+	} else {
+		p, err = hashesProofFrom(b64, parts[0], path, size, engine, flagged.TruncatedSize)
+	}
+	if err != nil {
+		return
+	}
+	p.DomainSeparation = flagged.DomainSeparation
+	p.Salt = flagged.Salt
+	p.TruncatedSize = flagged.TruncatedSize
+	p.Arity = flagged.Arity
+	p.SortedPairs = flagged.SortedPairs
+	p.Keyed = flagged.Keyed
+	return
+}
+
+//--- utility
+
+func (p *Proof) encode(trailHex, engine string) string {
+	str := fmt.Sprintf("%s.%s.%s.%d", trailHex, p.Path, engine, p.Size)
+	var flags []string
+	if p.DomainSeparation {
+		flags = append(flags, DOMAIN_SEPARATION_FLAG)
+	}
+	if len(p.Salt) != 0 {
+		flags = append(flags, fmt.Sprintf("%s=%s", SALT_FLAG, utls.ToHex(p.Salt)))
+	}
+	if p.TruncatedSize != 0 {
+		flags = append(flags, fmt.Sprintf("%s=%d", TRUNCATED_FLAG, p.TruncatedSize))
+	}
+	if p.Arity > 2 {
+		flags = append(flags, fmt.Sprintf("%s=%d", ARITY_FLAG, p.Arity))
+	}
+	if p.SortedPairs {
+		flags = append(flags, SORTED_FLAG)
+	}
+	if p.Keyed {
+		flags = append(flags, KEYED_FLAG)
+	}
+	if len(flags) != 0 {
+		str += "." + strings.Join(flags, ",")
+	}
+	return base64.StdEncoding.EncodeToString([]byte(str))
+}
+
+func (p *Proof) setFlags(b64, flags string) error {
+	for _, flag := range strings.Split(flags, ",") {
+		name, value, _ := strings.Cut(flag, "=")
+		switch name {
+		case DOMAIN_SEPARATION_FLAG:
+			p.DomainSeparation = true
+		case SALT_FLAG:
+			salt, err := utls.FromHex(value)
+			if err != nil || len(salt) == 0 {
+				return exception.NewInvalidMerkleProofError(b64)
+			}
+			p.Salt = salt
+		case TRUNCATED_FLAG:
+			truncatedSize, err := strconv.Atoi(value)
+			if err != nil || truncatedSize <= 0 {
+				return exception.NewInvalidMerkleProofError(b64)
+			}
+			p.TruncatedSize = truncatedSize
+		case ARITY_FLAG:
+			arity, err := strconv.Atoi(value)
+			if err != nil || arity < 2 || arity > MAX_ARITY {
+				return exception.NewInvalidMerkleProofError(b64)
 			}
-			trail = append(trail, bytes)
+			p.Arity = arity
+		case SORTED_FLAG:
+			if value != "" {
+				return exception.NewInvalidMerkleProofError(b64)
+			}
+			p.SortedPairs = true
+		case KEYED_FLAG:
+			if value != "" {
+				return exception.NewInvalidMerkleProofError(b64)
+			}
+			p.Keyed = true
+		default:
+			return exception.NewInvalidMerkleProofError(b64)
 		}
-		p = NewProof(trail, path, size, engine)
-	default:
+	}
+	return nil
+}
+
+func hashesProofFrom(b64, trailHex string, path Path, size int, engine string, truncatedSize int) (p *Proof, err error) {
+	digestSize, err := hash.GetDigestSize(engine)
+	if err == nil && truncatedSize != 0 {
+		err = hash.ValidateTruncatedSize(engine, truncatedSize)
+		digestSize = truncatedSize
+	}
+	if err != nil || len(trailHex)%(digestSize*2) != 0 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	hashes := regexp.MustCompile(fmt.Sprintf("(.{%d})", digestSize*2)).FindAllString(trailHex, -1)
+	if hashes == nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	var trail hash.Hashes
+	for _, h := range hashes {
+		bytes, e := utls.FromHex(h)
+		if e != nil {
+			err = exception.NewInvalidMerkleProofError(b64)
+			return
+		}
+		trail = append(trail, bytes)
+	}
+	p = NewProof(trail, path, size, engine)
+	return
+}
+
+func (p *Proof) toString() (str string, err error) {
+	if p.Multihash {
+		if str, err = p.multihashString(); err != nil {
+			err = exception.NewInvalidMerkleProofError(err.Error())
+		}
+		return
+	}
+	var hashes []string
+	for _, h := range p.Trail {
+		hashes = append(hashes, utls.ToHex(h))
+	}
+	str = p.encode(strings.Join(hashes, ""), p.Engine)
+	return
+}
+
+func (p *Proof) multihashString() (str string, err error) {
+	var hashes []string
+	for _, h := range p.Trail {
+		mh, e := hash.ToMultihash(h, p.Engine)
+		if e != nil {
+			err = e
+			return
+		}
+		hashes = append(hashes, utls.ToHex(mh))
+	}
+	str = p.encode(strings.Join(hashes, ""), hash.MULTIHASH)
+	return
+}
+
+func multihashProofFrom(b64, trailHex string, path Path, size int) (p *Proof, err error) {
+	bytes, err := utls.FromHex(trailHex)
+	if err != nil || len(bytes) == 0 {
 		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	var trail hash.Hashes
+	usingEngine := ""
+	for len(bytes) > 0 {
+		h, engine, n, e := hash.ReadMultihash(bytes)
+		if e != nil || (usingEngine != "" && engine != usingEngine) {
+			err = exception.NewInvalidMerkleProofError(b64)
+			return
+		}
+		usingEngine = engine
+		trail = append(trail, h)
+		bytes = bytes[n:]
 	}
+	p = NewProof(trail, path, size, usingEngine)
+	p.Multihash = true
 	return
 }
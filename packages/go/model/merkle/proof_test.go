@@ -36,4 +36,69 @@ func TestMerkleProof(t *testing.T) {
 
 	_, err = merkle.ProofFrom("not-a-valid-proof")
 	assert.Error(t, err, "invalid proof: not-a-valid-proof")
+
+	sha512, err := hash.BuildFunction(hash.SHA_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof512 := merkle.NewProof(hash.Hashes{sha512([]byte("data1")), sha512([]byte("data2"))}, "10", 3, hash.SHA_512)
+	instance, err = merkle.ProofFrom(proof512.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.Engine, hash.SHA_512)
+	assert.Equal(t, len(instance.Trail), 2)
+	assert.Assert(t, bytes.Equal(instance.Trail[1], sha512([]byte("data2"))))
+	assert.Equal(t, instance.String(), proof512.String())
+
+	// Base64("12201234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1220abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789.101.multihash.5")
+	mhRef := "MTIyMDEyMzQ1Njc4OTBhYmNkZWYxMjM0NTY3ODkwYWJjZGVmMTIzNDU2Nzg5MGFiY2RlZjEyMzQ1Njc4OTBhYmNkZWYxMjIwYWJjZGVmMDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWYwMTIzNDU2Nzg5YWJjZGVmMDEyMzQ1Njc4OS4xMDEubXVsdGloYXNoLjU="
+	proof.Multihash = true
+	assert.Equal(t, proof.String(), mhRef)
+	instance, err = merkle.ProofFrom(mhRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, instance.Multihash)
+	assert.Equal(t, instance.Engine, hash.SHA_256)
+	assert.Assert(t, bytes.Equal(instance.Trail[1], utls.Must(utls.FromHex("abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"))))
+
+	proof.Multihash = false
+	proof.DomainSeparation = true
+	instance, err = merkle.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, instance.DomainSeparation)
+	assert.Equal(t, instance.String(), proof.String())
+
+	proof.Salt = utls.Must(utls.FromHex("0123456789abcdef"))
+	instance, err = merkle.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, instance.DomainSeparation)
+	assert.DeepEqual(t, instance.Salt, proof.Salt)
+	assert.Equal(t, instance.String(), proof.String())
+
+	truncated := merkle.NewProof(hash.Hashes{hashes[0][:16], hashes[1][:16]}, "101", 5)
+	truncated.TruncatedSize = 16
+	instance, err = merkle.ProofFrom(truncated.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.TruncatedSize, 16)
+	assert.Equal(t, len(instance.Trail), 2)
+	assert.Assert(t, bytes.Equal(instance.Trail[1], hashes[1][:16]))
+	assert.Equal(t, instance.String(), truncated.String())
+
+	// Base64("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef.1.sha-256.2.unknown")
+	unknownFlag := "MTIzNDU2Nzg5MGFiY2RlZjEyMzQ1Njc4OTBhYmNkZWYxMjM0NTY3ODkwYWJjZGVmMTIzNDU2Nzg5MGFiY2RlZi4xLnNoYS0yNTYuMi51bmtub3du"
+	_, err = merkle.ProofFrom(unknownFlag)
+	assert.Error(t, err, "invalid proof: "+unknownFlag)
+
+	// Base64("1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef.1.sha-512.2")
+	wrongSize := "MTIzNDU2Nzg5MGFiY2RlZjEyMzQ1Njc4OTBhYmNkZWYxMjM0NTY3ODkwYWJjZGVmMTIzNDU2Nzg5MGFiY2RlZi4xLnNoYS01MTIuMg=="
+	_, err = merkle.ProofFrom(wrongSize)
+	assert.Error(t, err, "invalid proof: "+wrongSize)
 }
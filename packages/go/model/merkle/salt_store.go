@@ -0,0 +1,55 @@
+package merkle
+
+import (
+	"sync"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// SALT_SIZE is the length in bytes of the random salt generated for each leaf of a salted tree
+const SALT_SIZE = 32
+
+// SaltStore is the side store of the salts of a salted tree, indexed by the leaves they were used for.
+// The salt of an erased leaf is deleted, so that its source can't be confirmed from an old proof anymore.
+type SaltStore interface {
+	Delete(leaf hash.Hash) error
+	Get(leaf hash.Hash) (salt []byte, found bool)
+	Set(leaf hash.Hash, salt []byte) error
+}
+
+type memorySaltStore struct {
+	mu    sync.RWMutex
+	salts map[string][]byte
+}
+
+// Delete ...
+func (s *memorySaltStore) Delete(leaf hash.Hash) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.salts, utls.ToHex(leaf))
+	return nil
+}
+
+// Get ...
+func (s *memorySaltStore) Get(leaf hash.Hash) (salt []byte, found bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	salt, found = s.salts[utls.ToHex(leaf)]
+	return
+}
+
+// Set ...
+func (s *memorySaltStore) Set(leaf hash.Hash, salt []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.salts[utls.ToHex(leaf)] = salt
+	return nil
+}
+
+// NewMemorySaltStore returns a salt store simply held in memory, ie. the default store of a salted tree
+func NewMemorySaltStore() SaltStore {
+	return &memorySaltStore{
+		salts: make(map[string][]byte),
+	}
+}
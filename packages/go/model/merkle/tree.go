@@ -2,7 +2,10 @@ package merkle
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 
 	"github.com/cyrildever/go-utls/common/packer"
@@ -11,38 +14,40 @@ import (
 	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
 )
 
+// RFC 6962 domain separation prefixes
+const (
+	LEAF_PREFIX byte = 0x00
+	NODE_PREFIX byte = 0x01
+)
+
 //--- TYPES
 
 // Tree
 type Tree struct {
-	isReady      bool
-	hashFunction hash.Function
-	leaves       hash.Hashes
-	leavesHex    []string
-	levels       []hash.Hashes
-	options      *TreeOptions
+	isReady        bool
+	hashFunction   hash.Function
+	streamFunction hash.StreamFunction
+	leaves         hash.Hashes
+	leavesHex      []string
+	levels         []hash.Hashes
+	options        *TreeOptions
+	salts          SaltStore
 }
 
 //--- METHODS
 
-// AddLeaves adds either sources (by passing `true` to the first parameter) or hashes
+// AddLeaves sets the leaves of the tree from either sources (by passing `true` to the first parameter) or hashes, replacing any existing leaf,
+// and returns the proofs of all leaves (see `Append()` to add leaves to an existing tree).
+// In a salted tree, each source is salted with a new random salt.
 func (t *Tree) AddLeaves(doHash bool, data ...[]byte) (proofs []*Proof, err error) {
 	t.isReady = false
 	if len(data) == 0 {
 		err = fmt.Errorf("empty tree")
 		return
 	}
-	leaves := hash.Hashes{}
-	for _, d := range data {
-		if doHash {
-			if h := t.hashFunction(d); h != nil {
-				leaves = append(leaves, h)
-			}
-		} else {
-			if hash.IsCorrect(d, t.GetEngine()) {
-				leaves = append(leaves, d)
-			}
-		}
+	leaves, err := t.toLeaves(doHash, data)
+	if err != nil {
+		return
 	}
 	if t.options.Sort {
 		leaves = hash.SortHashes(leaves)
@@ -50,12 +55,92 @@ func (t *Tree) AddLeaves(doHash bool, data ...[]byte) (proofs []*Proof, err erro
 	t.leaves = leaves
 	leavesHex := []string{}
 	for _, leaf := range t.leaves {
-		leavesHex = append(leavesHex, utls.ToHex(leaf))
+		leavesHex = append(leavesHex, t.encode(leaf))
 	}
 	t.leavesHex = leavesHex
 	return t.make()
 }
 
+// AddLeafReaders adds the sources read from the passed readers, each one being hashed incrementally, eg. to build a tree over large files
+func (t *Tree) AddLeafReaders(readers ...io.Reader) (proofs []*Proof, err error) {
+	t.isReady = false
+	if len(readers) == 0 {
+		err = fmt.Errorf("empty tree")
+		return
+	}
+	leaves := hash.Hashes{}
+	for i, r := range readers {
+		salt, e := t.newSalt()
+		if e != nil {
+			err = e
+			return
+		}
+		if salt != nil {
+			r = io.MultiReader(bytes.NewReader(salt), r)
+		}
+		if t.options.DomainSeparation {
+			r = io.MultiReader(bytes.NewReader([]byte{LEAF_PREFIX}), r)
+		}
+		h, e := t.streamFunction(r)
+		if e != nil {
+			err = e
+			return
+		}
+		if !hash.IsCorrect(h, t.GetEngine(), t.options.TruncatedSize) {
+			err = fmt.Errorf("invalid source at index %d: unable to hash it with %s", i, t.GetEngine())
+			return
+		}
+		if salt != nil {
+			if err = t.salts.Set(h, salt); err != nil {
+				return
+			}
+		}
+		leaves = append(leaves, h)
+	}
+	return t.AddLeaves(false, leaves...)
+}
+
+// Append adds either sources (by passing `true` to the first parameter) or hashes after the existing leaves of the tree,
+// only recomputing its right-hand side, ie. O(k log n) for k new leaves in a tree of size n, and returns the proofs of the new leaves only.
+//
+// NB: The leaves of a sorted tree can't be appended to.
+func (t *Tree) Append(doHash bool, data ...[]byte) (proofs []*Proof, err error) {
+	if len(t.leaves) == 0 {
+		return t.AddLeaves(doHash, data...)
+	}
+	if !t.isReady {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	if t.options.Sort {
+		err = fmt.Errorf("unable to append to a sorted tree")
+		return
+	}
+	leaves, err := t.toLeaves(doHash, data)
+	if err != nil {
+		return
+	}
+	if len(leaves) == 0 {
+		err = fmt.Errorf("empty tree")
+		return
+	}
+	from := len(t.leaves)
+	t.leaves = append(t.leaves, leaves...)
+	for _, leaf := range leaves {
+		t.leavesHex = append(t.leavesHex, t.encode(leaf))
+	}
+	t.build(from)
+	for i := from; i < len(t.leaves); i++ {
+		if proof, found := t.proofAt(i); found {
+			proofs = append(proofs, proof)
+		} else {
+			err = fmt.Errorf("unable to retrive proof")
+			return
+		}
+	}
+	return
+}
+
 // Depth returns the depth of the tree, ie. the number of levels excluding the root hash
 func (t *Tree) Depth() (depth int, err error) {
 	if !t.isReady {
@@ -80,41 +165,43 @@ func (t *Tree) GetProof(leaf hash.Hash) (p *Proof, found bool) {
 	if index == -1 {
 		return
 	}
-	depth, err := t.Depth()
-	if err != nil {
-		return
-	}
-	path, err := NewPath(index, t.Size(), depth)
-	if err != nil {
-		return
-	}
-	trail := hash.Hashes{}
-	for level, idx := range path {
-		if i, err := strconv.Atoi(string(idx)); err == nil {
-			trail = append(trail, t.levels[level+1][i])
-		}
-	}
-	if len(trail) == 0 {
-		return
-	}
-	return NewProof(trail, path, t.Size(), t.GetEngine()), true
+	return t.proofAt(index)
 }
 
-// GetRootHash returns the hexadecimal representation of the root hash of the current Merkle tree
+// GetRootHash returns the hexadecimal representation of the root hash of the current Merkle tree,
+// ie. of its multihash if the tree uses the multihash representation
 func (t *Tree) GetRootHash() (rootHash string, err error) {
 	if !t.isReady {
 		err = exception.NewTreeNotBuiltError()
 		return
 	}
-	rootHash = utls.ToHex(t.levels[0][0])
+	rootHash = t.encode(t.levels[0][0])
 	return
 }
 
+// HashLeaf returns the hash of the passed source as a leaf of the current Merkle tree, eg. to check a proof against it,
+// using the passed salt if any (see `Proof.Salt`)
+func (t *Tree) HashLeaf(data []byte, salt ...[]byte) hash.Hash {
+	item := []byte{}
+	if t.options.DomainSeparation {
+		item = append(item, LEAF_PREFIX)
+	}
+	if len(salt) == 1 {
+		item = append(item, salt[0]...)
+	}
+	return t.hashFunction(append(item, data...))
+}
+
 // IsSorted returns `true` if the current Merkle tree leaves are sorted, `false` otherwise
 func (t *Tree) IsSorted() bool {
 	return t.options.Sort
 }
 
+// IsTombstoned returns `true` if the leaf at the passed index was tombstoned, `false` otherwise
+func (t *Tree) IsTombstoned(index int) bool {
+	return index >= 0 && index < len(t.leaves) && bytes.Equal(t.leaves[index], t.tombstone())
+}
+
 // JSON returns the JSON-stringified representation of the current Merkle tree
 //
 // IMPORTANT: Use with caution!
@@ -123,7 +210,7 @@ func (t *Tree) JSON() (json string, err error) {
 		err = exception.NewTreeNotBuiltError()
 		return
 	}
-	opts, err := packer.JSONMarshal(*t.options)
+	opts, err := packer.JSONMarshal(t.options)
 	if err != nil {
 		return
 	}
@@ -135,11 +222,113 @@ func (t *Tree) JSON() (json string, err error) {
 	return
 }
 
+// RemoveLeaf takes the leaf at the passed index out of the tree, the following leaves being shifted to the left,
+// and returns the new root hash, or an empty string if the tree is now empty.
+//
+// In a salted tree, the salt of the leaf is deleted from the salt store.
+//
+// NB: As the indices of the following leaves change, all proofs should be retrieved again (see `Tombstone()` otherwise).
+func (t *Tree) RemoveLeaf(index int) (rootHash string, err error) {
+	if !t.isReady {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	if index < 0 || index >= len(t.leaves) {
+		err = fmt.Errorf("index out of range: %d", index)
+		return
+	}
+	leaf := t.leaves[index]
+	t.leaves = append(t.leaves[:index:index], t.leaves[index+1:]...)
+	t.leavesHex = append(t.leavesHex[:index:index], t.leavesHex[index+1:]...)
+	if len(t.leaves) == 0 {
+		t.isReady = false
+		t.levels = []hash.Hashes{}
+		err = t.deleteSalt(leaf)
+		return
+	}
+	t.build(index)
+	if err = t.deleteSalt(leaf); err != nil {
+		return
+	}
+	return t.GetRootHash()
+}
+
+// SetSaltStore sets the side store of the salts of the current Merkle tree, eg. after rebuilding a salted tree with `TreeFrom()`
+func (t *Tree) SetSaltStore(store SaltStore) {
+	t.salts = store
+}
+
 // Size returns the number of leaves
 func (t *Tree) Size() int {
 	return len(t.leaves)
 }
 
+// Tombstone replaces the leaf at the passed index with the tombstone marker, ie. a zero digest, eg. to erase personal data,
+// keeping the index and the path of every other leaf unchanged, and returns the new root hash.
+// In a salted tree, the salt of the leaf is deleted from the salt store.
+func (t *Tree) Tombstone(index int) (rootHash string, err error) {
+	var leaf hash.Hash
+	if t.isReady && index >= 0 && index < len(t.leaves) {
+		leaf = t.leaves[index]
+	}
+	if rootHash, _, err = t.UpdateLeaves(map[int]hash.Hash{index: t.tombstone()}); err != nil {
+		return
+	}
+	err = t.deleteSalt(leaf)
+	return
+}
+
+// UpdateLeaf replaces the leaf at the passed index with the passed hash, only recomputing its ancestors,
+// and returns the new root hash and the new proof of the leaf (see `UpdateLeaves()`)
+func (t *Tree) UpdateLeaf(index int, leaf hash.Hash) (rootHash string, proofs []*Proof, err error) {
+	return t.UpdateLeaves(map[int]hash.Hash{index: leaf})
+}
+
+// UpdateLeaves replaces the leaves at the passed indices with the passed hashes, each common ancestor being only recomputed once,
+// and returns the new root hash and the new proofs of the updated leaves in the order of their indices.
+//
+// NB: As the root hash changes, the proofs of the other leaves should be retrieved again. The leaves of a sorted tree can't be updated.
+func (t *Tree) UpdateLeaves(leaves map[int]hash.Hash) (rootHash string, proofs []*Proof, err error) {
+	if !t.isReady {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	if t.options.Sort {
+		err = fmt.Errorf("unable to update a sorted tree")
+		return
+	}
+	dirty := []int{}
+	for index, leaf := range leaves {
+		if index < 0 || index >= len(t.leaves) {
+			err = fmt.Errorf("index out of range: %d", index)
+			return
+		}
+		if !hash.IsCorrect(leaf, t.GetEngine(), t.options.TruncatedSize) {
+			err = fmt.Errorf("invalid leaf at index %d", index)
+			return
+		}
+		dirty = append(dirty, index)
+	}
+	sort.Ints(dirty)
+	for _, index := range dirty {
+		t.leaves[index] = leaves[index]
+		t.leavesHex[index] = t.encode(leaves[index])
+	}
+	t.rehash(dirty)
+	if rootHash, err = t.GetRootHash(); err != nil {
+		return
+	}
+	for _, index := range dirty {
+		if proof, found := t.proofAt(index); found {
+			proofs = append(proofs, proof)
+		} else {
+			err = fmt.Errorf("unable to retrive proof")
+			return
+		}
+	}
+	return
+}
+
 // UseDoubleHash returns `true` if the current Merkle tree uses double hashing, `false` otherwise
 func (t *Tree) UseDoubleHash() bool {
 	return t.options.DoubleHash
@@ -150,26 +339,161 @@ func (t *Tree) ValidateProof(proof *Proof, leaf hash.Hash, rootHash string, rebu
 	if r, err := t.GetRootHash(); err != nil || r != rootHash {
 		return false
 	}
+	if proof.DomainSeparation != t.options.DomainSeparation || proof.TruncatedSize != t.options.TruncatedSize || max(proof.Arity, 2) != t.arity() ||
+		proof.SortedPairs != t.options.SortedPairs || proof.Keyed != t.options.Keyed {
+		return false
+	}
 	if len(rebuildProof) == 1 && rebuildProof[0] {
 		rebuilt, found := t.GetProof(leaf)
-		return found && rebuilt.String() == proof.String()
+		if !found {
+			return false
+		}
+		expected, err := rebuilt.toString()
+		if err != nil {
+			return false
+		}
+		actual, err := proof.toString()
+		return err == nil && actual == expected
+	} else if t.arity() > 2 {
+		h, ok := t.kAryRoot(proof, leaf)
+		return ok && t.encode(h) == rootHash
 	} else {
 		path := utls.Reverse(proof.Path)
 		trail := reverse(proof.Trail)
+		if !t.options.SortedPairs && len(path) != len(trail) {
+			return false
+		}
 		h := leaf
 		for idx, current := range trail {
-			if string(path[idx]) == RIGHT {
-				h = t.hashFunction(append(current, h...))
+			if t.options.SortedPairs {
+				h = t.hashNode(h, current)
+			} else if string(path[idx]) == RIGHT {
+				h = t.hashNode(current, h)
 			} else {
-				h = t.hashFunction(append(h, current...))
+				h = t.hashNode(h, current)
 			}
 		}
-		return utls.ToHex(h) == rootHash
+		return t.encode(h) == rootHash
 	}
 }
 
 // For internal use only
 
+// build (re)computes the nodes of all levels from the passed index of the leaves, keeping the ones on its left.
+// NB: Levels are stored top-down, ie. the root level first and the leaves last.
+func (t *Tree) build(from int) {
+	levels := []hash.Hashes{t.leaves}
+	for i := len(t.levels) - 2; i >= 0; i-- {
+		levels = append(levels, t.levels[i])
+	}
+	level := 0
+	for ; len(levels[level]) > 1; level++ {
+		children := levels[level]
+		if level+1 == len(levels) {
+			levels = append(levels, hash.Hashes{})
+		}
+		nodes := levels[level+1][:from/t.arity()]
+		for parent := from / t.arity(); t.arity()*parent < len(children); parent++ {
+			nodes = append(nodes, t.parentNode(children, parent))
+		}
+		levels[level+1] = nodes
+		from /= t.arity()
+	}
+	t.levels = []hash.Hashes{}
+	for ; level >= 0; level-- {
+		t.levels = append(t.levels, levels[level])
+	}
+}
+
+func (t *Tree) arity() int {
+	if t.options.Arity > 2 {
+		return t.options.Arity
+	}
+	return 2
+}
+
+// deleteSalt removes the salt of the leaf at the passed index from the salt store, unless the same leaf is found at another index
+func (t *Tree) deleteSalt(leaf hash.Hash) error {
+	if !t.options.Salted || t.salts == nil {
+		return nil
+	}
+	for _, l := range t.leaves {
+		if bytes.Equal(l, leaf) {
+			return nil
+		}
+	}
+	return t.salts.Delete(leaf)
+}
+
+func (t *Tree) encode(h hash.Hash) string {
+	if t.options.Multihash {
+		if mh, err := hash.ToMultihash(h, t.GetEngine()); err == nil {
+			return utls.ToHex(mh)
+		}
+	}
+	return utls.ToHex(h)
+}
+
+func (t *Tree) hashNode(children ...hash.Hash) hash.Hash {
+	if t.options.SortedPairs && len(children) == 2 && bytes.Compare(children[0], children[1]) > 0 {
+		children = hash.Hashes{children[1], children[0]}
+	}
+	node := []byte{}
+	if t.options.DomainSeparation {
+		node = append(node, NODE_PREFIX)
+	}
+	for _, child := range children {
+		node = append(node, child...)
+	}
+	return t.hashFunction(node)
+}
+
+// kAryRoot computes the root hash from the passed proof of a k-ary tree, the number of siblings at each level being found from the size of the tree
+func (t *Tree) kAryRoot(proof *Proof, leaf hash.Hash) (h hash.Hash, ok bool) {
+	k := t.arity()
+	lengths := []int{proof.Size}
+	for n := proof.Size; n > 1; {
+		n = (n + k - 1) / k
+		lengths = append(lengths, n)
+	}
+	if len(proof.Path) != len(lengths)-1 {
+		return
+	}
+	index := 0
+	positions := []int{}
+	for _, char := range proof.Path {
+		pos, err := strconv.ParseInt(string(char), 16, 0)
+		if err != nil || int(pos) >= k {
+			return
+		}
+		index = index*k + int(pos)
+		positions = append([]int{int(pos)}, positions...)
+	}
+	trail := proof.Trail
+	h = leaf
+	for level, pos := range positions {
+		if index >= lengths[level] {
+			return
+		}
+		start := index - pos
+		count := min(start+k, lengths[level]) - start - 1
+		if count > len(trail) {
+			return
+		}
+		siblings := trail[len(trail)-count:]
+		trail = trail[:len(trail)-count]
+		if count > 0 {
+			children := hash.Hashes{}
+			children = append(children, siblings[:pos]...)
+			children = append(children, h)
+			children = append(children, siblings[pos:]...)
+			h = t.hashNode(children...)
+		}
+		index /= k
+	}
+	return h, len(trail) == 0
+}
+
 func (t *Tree) make() (proofs []*Proof, err error) {
 	if len(t.leaves) == 0 {
 		err = fmt.Errorf("empty tree")
@@ -177,19 +501,13 @@ func (t *Tree) make() (proofs []*Proof, err error) {
 	}
 
 	// Build the actual tree
-	t.levels = append([]hash.Hashes{t.leaves}, t.levels...)
-	for len(t.levels[0]) > 1 {
-		t.levels = append([]hash.Hashes{t.nextLevel()}, t.levels...)
-	}
-	if len(t.levels) == 0 {
-		err = fmt.Errorf("empty tree")
-		return
-	}
+	t.levels = []hash.Hashes{}
+	t.build(0)
 	t.isReady = true
 
 	// Retrieve the proofs
-	for _, leaf := range t.leaves {
-		if proof, found := t.GetProof(leaf); found {
+	for index := range t.leaves {
+		if proof, found := t.proofAt(index); found {
 			proofs = append(proofs, proof)
 		} else {
 			err = fmt.Errorf("unable to retrive proof")
@@ -200,19 +518,134 @@ func (t *Tree) make() (proofs []*Proof, err error) {
 	return
 }
 
-func (t *Tree) nextLevel() hash.Hashes {
-	nodes := hash.Hashes{}
-	fromLevel := t.levels[0]
-	fromLevelCount := len(fromLevel)
-	for i := 0; i < fromLevelCount; i += 2 {
-		if i+1 <= fromLevelCount-1 {
-			nodes = append(nodes, t.hashFunction(append(fromLevel[i], fromLevel[i+1]...)))
+func (t *Tree) newSalt() (salt []byte, err error) {
+	if !t.options.Salted {
+		return
+	}
+	salt = make([]byte, SALT_SIZE)
+	if _, err = rand.Read(salt); err != nil {
+		salt = nil
+	}
+	return
+}
+
+// parentNode computes the node at the passed index from its children in the passed level, applying the odd-node policy to an unpaired child
+func (t *Tree) parentNode(children hash.Hashes, parent int) hash.Hash {
+	i := t.arity() * parent
+	if i+1 < len(children) {
+		return t.hashNode(children[i:min(i+t.arity(), len(children))]...)
+	}
+	if t.options.OddNodePolicy == ODD_NODE_DUPLICATE {
+		return t.hashNode(children[i], children[i])
+	}
+	// Odd number promoted to the next level, which is also what splitting at the largest power of two comes down to
+	return children[i]
+}
+
+// proofAt builds the proof of the leaf at the passed index bottom-up, levels where the node was promoted being skipped
+func (t *Tree) proofAt(index int) (p *Proof, found bool) {
+	leaf := t.leaves[index]
+	trail := hash.Hashes{}
+	var path Path
+	for level := len(t.levels) - 1; level > 0; level-- {
+		nodes := t.levels[level]
+		if t.arity() > 2 {
+			// All siblings with the position of the node among its siblings, even when promoted
+			start := index / t.arity() * t.arity()
+			siblings := hash.Hashes{}
+			siblings = append(siblings, nodes[start:index]...)
+			siblings = append(siblings, nodes[index+1:min(start+t.arity(), len(nodes))]...)
+			trail = append(siblings, trail...)
+			path = strconv.FormatInt(int64(index-start), 16) + path
+		} else if index%2 == 1 {
+			trail = append(hash.Hashes{nodes[index-1]}, trail...)
+			path = RIGHT + path
+		} else if index+1 < len(nodes) {
+			trail = append(hash.Hashes{nodes[index+1]}, trail...)
+			path = LEFT + path
+		} else if t.options.OddNodePolicy == ODD_NODE_DUPLICATE {
+			trail = append(hash.Hashes{nodes[index]}, trail...)
+			path = LEFT + path
+		}
+		index /= t.arity()
+	}
+	if len(trail) == 0 {
+		return
+	}
+	if t.options.SortedPairs {
+		path = ""
+	}
+	p = NewProof(trail, path, t.Size(), t.GetEngine())
+	p.Multihash = t.options.Multihash
+	p.DomainSeparation = t.options.DomainSeparation
+	p.TruncatedSize = t.options.TruncatedSize
+	p.SortedPairs = t.options.SortedPairs
+	p.Keyed = t.options.Keyed
+	if t.arity() > 2 {
+		p.Arity = t.arity()
+	}
+	if t.options.Salted {
+		if salt, found := t.salts.Get(leaf); found {
+			p.Salt = salt
+		}
+	}
+	return p, true
+}
+
+// rehash recomputes the ancestors of the leaves at the passed sorted indices bottom-up, each node only once
+func (t *Tree) rehash(dirty []int) {
+	for level := len(t.levels) - 1; level > 0; level-- {
+		children := t.levels[level]
+		nodes := t.levels[level-1]
+		parents := []int{}
+		for _, i := range dirty {
+			parent := i / t.arity()
+			if len(parents) != 0 && parents[len(parents)-1] == parent {
+				continue
+			}
+			parents = append(parents, parent)
+			nodes[parent] = t.parentNode(children, parent)
+		}
+		dirty = parents
+	}
+}
+
+func (t *Tree) tombstone() hash.Hash {
+	size := t.options.TruncatedSize
+	if size == 0 {
+		size, _ = hash.GetDigestSize(t.GetEngine())
+	}
+	return make(hash.Hash, size)
+}
+
+func (t *Tree) toLeaves(doHash bool, data [][]byte) (leaves hash.Hashes, err error) {
+	leaves = hash.Hashes{}
+	for i, d := range data {
+		if doHash {
+			salt, e := t.newSalt()
+			if e != nil {
+				err = e
+				return
+			}
+			h := t.HashLeaf(d, salt)
+			if !hash.IsCorrect(h, t.GetEngine(), t.options.TruncatedSize) {
+				// eg. data that isn't made of field elements for Poseidon
+				err = fmt.Errorf("invalid source at index %d: unable to hash it with %s", i, t.GetEngine())
+				return
+			}
+			if salt != nil {
+				if err = t.salts.Set(h, salt); err != nil {
+					return
+				}
+			}
+			leaves = append(leaves, h)
 		} else {
-			// Odd number promoted to the next level
-			nodes = append(nodes, fromLevel[i])
+			if hash.IsCorrect(d, t.GetEngine(), t.options.TruncatedSize) {
+				leaves = append(leaves, d)
+			}
 		}
 	}
-	return nodes
+	return
 }
 
 //--- FUNCTIONS
@@ -223,17 +656,68 @@ func NewTree(options ...*TreeOptions) (t *Tree, err error) {
 	if len(options) == 1 && options[0] != nil {
 		opts = options[0]
 	}
-	hFn, err := hash.BuildFunction(opts.Engine, opts.DoubleHash)
+	switch opts.OddNodePolicy {
+	case "", ODD_NODE_PROMOTE, ODD_NODE_DUPLICATE, ODD_NODE_SPLIT:
+	default:
+		err = fmt.Errorf("invalid odd node policy: %s", opts.OddNodePolicy)
+		return
+	}
+	if opts.Keyed && len(opts.Key) == 0 {
+		msg := "missing key"
+		if opts.KeyID != "" {
+			msg = fmt.Sprintf("missing key %s", opts.KeyID)
+		}
+		err = exception.NewInvalidKeyError(msg)
+		return
+	}
+	if opts.Arity < 0 || opts.Arity == 1 || opts.Arity > MAX_ARITY {
+		err = fmt.Errorf("invalid arity: %d", opts.Arity)
+		return
+	}
+	if opts.Arity > 2 && ((opts.OddNodePolicy != "" && opts.OddNodePolicy != ODD_NODE_PROMOTE) || opts.SortedPairs) {
+		err = fmt.Errorf("invalid arity: %d-ary trees only promote unpaired nodes and can't sort pairs", opts.Arity)
+		return
+	}
+	if opts.Multihash {
+		if !hash.HasMultihashCode(opts.Engine) {
+			err = exception.NewInvalidMultihashError(fmt.Sprintf("no code for %s", opts.Engine))
+			return
+		}
+		if opts.DoubleHash || len(opts.Key) != 0 || opts.TruncatedSize != 0 {
+			err = exception.NewInvalidMultihashError("no code for keyed, double-hashed or truncated trees")
+			return
+		}
+	}
+	hFn, err := hash.BuildKeyedFunction(opts.Engine, opts.Key, opts.DoubleHash)
 	if err != nil {
 		return
 	}
+	sFn, err := hash.BuildKeyedStreamFunction(opts.Engine, opts.Key, opts.DoubleHash)
+	if err != nil {
+		return
+	}
+	if opts.TruncatedSize != 0 {
+		if err = hash.ValidateTruncatedSize(opts.Engine, opts.TruncatedSize); err != nil {
+			return
+		}
+		hFn = hash.TruncateFunction(hFn, opts.TruncatedSize)
+		sFn = hash.TruncateStreamFunction(sFn, opts.TruncatedSize)
+	}
+	salts := opts.Salts
+	if opts.Salted && salts == nil {
+		salts = NewMemorySaltStore()
+	}
+	normalised := *opts
+	normalised.Keyed = len(opts.Key) != 0
 	return &Tree{
-		isReady:      false,
-		hashFunction: hFn,
-		leaves:       hash.Hashes{},
-		leavesHex:    []string{},
-		levels:       []hash.Hashes{},
-		options:      opts,
+		isReady:        false,
+		hashFunction:   hFn,
+		streamFunction: sFn,
+		leaves:         hash.Hashes{},
+		leavesHex:      []string{},
+		levels:         []hash.Hashes{},
+		options:        &normalised,
+		salts:          salts,
 	}, nil
 }
 
@@ -242,8 +726,10 @@ type decodedJSON struct {
 	Leaves  []string     `json:"leaves"`
 }
 
-// TreeFrom builds a `MerkleTree` instance from the passed string
-func TreeFrom(json string) (t *Tree, err error) {
+// TreeFrom builds a `MerkleTree` instance from the passed string, using the passed key if it was a keyed tree,
+// a key passed for an unkeyed tree being rejected.
+// With the multihash representation, the engine may be omitted from the options as it's then found from the leaves' prefix.
+func TreeFrom(json string, key ...[]byte) (t *Tree, err error) {
 	var decoded decodedJSON
 	if err = packer.JSONUnmarshal([]byte(json), &decoded); err != nil {
 		return
@@ -252,21 +738,53 @@ func TreeFrom(json string) (t *Tree, err error) {
 	if decoded.Options != nil {
 		opts = decoded.Options
 	}
-	tree, err := NewTree(opts)
-	if err != nil {
-		return
-	}
 	leavesHex := decoded.Leaves
 	leaves := hash.Hashes{}
 	for _, leaf := range leavesHex {
-		if l, e := utls.FromHex(leaf); e == nil {
-			leaves = append(leaves, l)
+		l, e := utls.FromHex(leaf)
+		if e != nil {
+			continue
+		}
+		if opts.Multihash {
+			h, engine, e := hash.FromMultihash(l)
+			if e != nil {
+				err = e
+				return
+			}
+			if opts.Engine == "" {
+				opts.Engine = engine
+			} else if engine != opts.Engine {
+				err = exception.NewInvalidMultihashError(fmt.Sprintf("%s leaf in a %s tree", engine, opts.Engine))
+				return
+			}
+			l = h
 		}
+		leaves = append(leaves, l)
 	}
 	if len(leaves) == 0 {
 		err = fmt.Errorf("empty tree")
 		return
 	}
+	if len(key) == 1 && len(key[0]) != 0 {
+		if !opts.Keyed {
+			err = exception.NewInvalidKeyError("unkeyed tree")
+			return
+		}
+		keyed := *opts
+		keyed.Key = key[0]
+		opts = &keyed
+	} else if opts.Keyed {
+		msg := "missing key"
+		if opts.KeyID != "" {
+			msg = fmt.Sprintf("missing key %s", opts.KeyID)
+		}
+		err = exception.NewInvalidKeyError(msg)
+		return
+	}
+	tree, err := NewTree(opts)
+	if err != nil {
+		return
+	}
 	if _, e := tree.AddLeaves(false, leaves...); e != nil {
 		err = e
 		return
@@ -5,12 +5,57 @@ import (
 )
 
 // TreeOptions ...
+//
+// NB: The optional `Key` turns the tree into a keyed tree (see `hash.BuildKeyedFunction()`) and is never serialised:
+// only the fact that the tree is keyed and the optional `KeyID` reference to the key are. `Keyed` is set by `NewTree()` from the presence of `Key`,
+// a tree flagged as keyed without any key being rejected.
+// Setting `Multihash` to `true` makes the leaves in the JSON representation, the root hash and the proofs self-describing multihashes.
+// Setting `DomainSeparation` to `true` follows RFC 6962 by prefixing the hashed data with 0x00 for leaves and 0x01 for nodes,
+// preventing any node from being passed off as a leaf (second-preimage attack).
+// Setting `Salted` to `true` hashes each source prefixed with a random salt kept in the `Salts` side store (an in-memory one by default),
+// so that leaves can't be guessed from low-entropy sources. Salts are never serialised but passed in the proof of each leaf.
+// Setting `TruncatedSize` keeps only the first bytes of every leaf and node digest to get more compact proofs, to the detriment of security
+// (see `hash.TruncateFunction()`).
+// `OddNodePolicy` sets how the last node of a level is handled when it has no sibling (see `ODD_NODE_PROMOTE`).
+// Setting `SortedPairs` to `true` hashes each pair of nodes in ascending byte order, as OpenZeppelin's `MerkleProof.verify()` expects,
+// so that proofs don't need any path.
+// Setting `Arity` to a number of children per node from 3 to `MAX_ARITY` builds a k-ary tree with fewer levels but bigger proofs
+// (0 or 2 for a binary tree): unpaired nodes are then always promoted and pairs can't be sorted.
 type TreeOptions struct {
-	DoubleHash bool   `json:"doubleHash"`
-	Engine     string `json:"engine"`
-	Sort       bool   `json:"sort"`
+	DoubleHash       bool      `json:"doubleHash"`
+	Engine           string    `json:"engine"`
+	Sort             bool      `json:"sort"`
+	Keyed            bool      `json:"keyed,omitempty"`
+	KeyID            string    `json:"keyId,omitempty"`
+	Multihash        bool      `json:"multihash,omitempty"`
+	DomainSeparation bool      `json:"domainSeparation,omitempty"`
+	Salted           bool      `json:"salted,omitempty"`
+	TruncatedSize    int       `json:"truncatedSize,omitempty"`
+	OddNodePolicy    string    `json:"oddNodePolicy,omitempty"`
+	SortedPairs      bool      `json:"sortedPairs,omitempty"`
+	Arity            int       `json:"arity,omitempty"`
+	Key              []byte    `json:"-"`
+	Salts            SaltStore `json:"-"`
 }
 
+// Odd-node policies
+const (
+	// ODD_NODE_PROMOTE promotes the unpaired node unchanged to the next level (default)
+	ODD_NODE_PROMOTE = "promote"
+
+	// ODD_NODE_DUPLICATE pairs the unpaired node with itself, as in Bitcoin.
+	//
+	// IMPORTANT: The trees of [a, b, c] and [a, b, c, c] then have the same root (CVE-2012-2459), so duplicate leaves should be rejected beforehand.
+	ODD_NODE_DUPLICATE = "duplicate"
+
+	// ODD_NODE_SPLIT splits the leaves at the largest power of two lower than their number, recursively, as in RFC 6962 or CometBFT.
+	// NB: This always builds the exact same tree as promoting unpaired nodes bottom-up, hence the same roots and proofs.
+	ODD_NODE_SPLIT = "split"
+)
+
+// MAX_ARITY is the maximum number of children of a node, ie. the position of a child fits in one hexadecimal character of a path
+const MAX_ARITY = 16
+
 // DEFAULT_TREE_OPTIONS sets double hash and sort to `false`, and engine to "sha-256"
 var DEFAULT_TREE_OPTIONS = NewTreeOptions(false, hash.SHA_256, false)
 
@@ -22,3 +67,14 @@ func NewTreeOptions(doubleHash bool, engine string, sort bool) *TreeOptions {
 		Sort:       sort,
 	}
 }
+
+// NewKeyedTreeOptions returns the options of a keyed tree using the passed key, optionally referenced by the passed identifier
+func NewKeyedTreeOptions(doubleHash bool, engine string, sort bool, key []byte, keyID ...string) *TreeOptions {
+	opts := NewTreeOptions(doubleHash, engine, sort)
+	opts.Keyed = len(key) != 0
+	opts.Key = key
+	if len(keyID) == 1 {
+		opts.KeyID = keyID[0]
+	}
+	return opts
+}
@@ -1,6 +1,13 @@
 package merkle_test
 
 import (
+	stdsha256 "crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	utls "github.com/cyrildever/go-utls/common/utils"
@@ -11,6 +18,8 @@ import (
 
 var sha256, _ = hash.BuildFunction(hash.SHA_256)
 
+var registeredEngines int32
+
 // TestAddLeaves ...
 func TestAddLeaves(t *testing.T) {
 	tree, err := merkle.NewTree()
@@ -24,6 +33,47 @@ func TestAddLeaves(t *testing.T) {
 	assert.Error(t, err, "empty tree")
 }
 
+// TestAddLeafReaders ...
+func TestAddLeafReaders(t *testing.T) {
+	data := []string{"data1", "data2", "data3", "data4", "data5"}
+	readers := []io.Reader{}
+	sources := [][]byte{}
+	for _, d := range data {
+		readers = append(readers, strings.NewReader(d))
+		sources = append(sources, []byte(d))
+	}
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(true, hash.SHA_256, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeafReaders(readers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := merkle.NewTree(merkle.NewTreeOptions(true, hash.SHA_256, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedProofs, err := expected.AddLeaves(true, sources...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHash, err := expected.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, expectedRootHash)
+	assert.Equal(t, proofs[1].String(), expectedProofs[1].String())
+
+	_, err = tree.AddLeafReaders()
+	assert.Error(t, err, "empty tree")
+}
+
 // TestGetProof ...
 func TestGetProof(t *testing.T) {
 	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
@@ -157,3 +207,1278 @@ func TestValidateProof(t *testing.T) {
 	found = tree.ValidateProof(toProve, sha256([]byte("data1")), "e9e1bc4a10c502ef995ede1914b0186ed288b8dde80c8c533a0f93a96490f995")
 	assert.Assert(t, found == false)
 }
+
+// TestMerkleTreeSHA512 ...
+func TestMerkleTreeSHA512(t *testing.T) {
+	sha512, err := hash.BuildFunction(hash.SHA_512, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(true, hash.SHA_512, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(rootHash), 128)
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree2, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree2.GetEngine(), hash.SHA_512)
+	assert.Assert(t, tree2.UseDoubleHash())
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	proof, err := merkle.ProofFrom(proofs[1].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, tree2.ValidateProof(proof, sha512([]byte("data2")), rootHash))
+	assert.Assert(t, !tree2.ValidateProof(proof, sha512([]byte("data1")), rootHash))
+}
+
+// TestMerkleTreeSHA3 ...
+func TestMerkleTreeSHA3(t *testing.T) {
+	sha3_256, err := hash.BuildFunction(hash.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3")}
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.SHA3_256, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree2, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree2.GetEngine(), hash.SHA3_256)
+	assert.Assert(t, tree2.IsSorted())
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	leaf := sha3_256([]byte("data2"))
+	proof, found := tree2.GetProof(leaf)
+	assert.Assert(t, found)
+	instance, err := merkle.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.Engine, hash.SHA3_256)
+	expected, _ := tree.GetProof(leaf)
+	assert.Equal(t, instance.String(), expected.String())
+	assert.Assert(t, tree2.ValidateProof(instance, leaf, rootHash))
+}
+
+// TestMerkleTreeKeccak ...
+func TestMerkleTreeKeccak(t *testing.T) {
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.KECCAK_256, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000001"))
+	two := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000002"))
+	proofs, err := tree.AddLeaves(false, one, two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Solidity: keccak256(abi.encodePacked(uint256(1), uint256(2)))
+	assert.Equal(t, rootHash, "e90b7bceb6e7df5418fb78d8ee546e97c83a08bbccc01a0644d599ccd2a7c2e0")
+	assert.Equal(t, proofs[0].Engine, hash.KECCAK_256)
+	assert.Assert(t, tree.ValidateProof(proofs[1], two, rootHash))
+}
+
+// TestKeyedMerkleTree ...
+func TestKeyedMerkleTree(t *testing.T) {
+	key := []byte("secret")
+	blake2b, err := hash.BuildKeyedFunction(hash.BLAKE2B_256, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4")}
+	tree, err := merkle.NewTree(merkle.NewKeyedTreeOptions(false, hash.BLAKE2B_256, false, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, tree.ValidateProof(proofs[0], blake2b([]byte("data1")), rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, !strings.Contains(json, utls.ToHex(key)) && !strings.Contains(json, "c2VjcmV0"))
+
+	rebuilt, err := merkle.TreeFrom(json, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuiltRootHash, err := rebuilt.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rebuiltRootHash, rootHash)
+
+	_, err = merkle.TreeFrom(json)
+	assert.Error(t, err, "invalid key: missing key")
+
+	// Keyed proofs are flagged and can't be validated by an unkeyed tree
+	assert.Assert(t, proofs[0].Keyed)
+	proof, err := merkle.ProofFrom(proofs[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, proof.Keyed)
+	decoded, err := base64.StdEncoding.DecodeString(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasSuffix(string(decoded), ".keyed"))
+	plain, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.BLAKE2B_256, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainProofs, err := plain.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainRootHash, err := plain.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainLeaf := plain.HashLeaf([]byte("data1"))
+	assert.Assert(t, !plainProofs[0].Keyed)
+	assert.Assert(t, plain.ValidateProof(plainProofs[0], plainLeaf, plainRootHash))
+	plainProofs[0].Keyed = true
+	assert.Assert(t, !plain.ValidateProof(plainProofs[0], plainLeaf, plainRootHash))
+
+	// A key can't be passed for an unkeyed tree
+	plainJSON, err := plain.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = merkle.TreeFrom(plainJSON, key)
+	assert.Error(t, err, "invalid key: unkeyed tree")
+
+	// A key in raw options is enough to make a keyed tree whose proofs survive a round trip
+	raw, err := merkle.NewTree(&merkle.TreeOptions{Engine: hash.BLAKE2B_256, Key: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawProofs, err := raw.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, rawProofs[0].Keyed)
+	rawJSON, err := raw.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.Contains(rawJSON, `"keyed":true`))
+	rebuilt, err = merkle.TreeFrom(rawJSON, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuiltRootHash, err = rebuilt.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rebuiltRootHash, rootHash)
+	assert.Assert(t, rebuilt.ValidateProof(rawProofs[0], blake2b([]byte("data1")), rebuiltRootHash))
+
+	_, err = merkle.NewTree(&merkle.TreeOptions{Engine: hash.BLAKE2B_256, Keyed: true, KeyID: "key-1"})
+	assert.Error(t, err, "invalid key: missing key key-1")
+}
+
+// TestHMACMerkleTree ...
+func TestHMACMerkleTree(t *testing.T) {
+	key := []byte("secret")
+	hmacSha256, err := hash.BuildKeyedFunction(hash.SHA_256, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("123-45-6789"), []byte("987-65-4321"), []byte("555-55-5555")}
+	tree, err := merkle.NewTree(merkle.NewKeyedTreeOptions(false, hash.SHA_256, false, key, "national-ids"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, tree.ValidateProof(proofs[0], hmacSha256([]byte("123-45-6789")), rootHash))
+	assert.Assert(t, !tree.ValidateProof(proofs[0], sha256([]byte("123-45-6789")), rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"keyed":true,"keyId":"national-ids"},"leaves":["`))
+	assert.Assert(t, !strings.Contains(json, utls.ToHex(sha256([]byte("123-45-6789")))))
+
+	_, err = merkle.TreeFrom(json)
+	assert.Error(t, err, "invalid key: missing key national-ids")
+
+	rebuilt, err := merkle.TreeFrom(json, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuiltRootHash, err := rebuilt.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rebuiltRootHash, rootHash)
+	assert.Assert(t, rebuilt.ValidateProof(proofs[0], hmacSha256([]byte("123-45-6789")), rootHash))
+
+}
+
+// TestMerkleTreeRegisteredEngine ...
+func TestMerkleTreeRegisteredEngine(t *testing.T) {
+	// The registry being global, a new name is used on each run, eg. with `go test -count=2`
+	engine := fmt.Sprintf("sha-224-%d", atomic.AddInt32(&registeredEngines, 1))
+	if err := hash.Register(engine, stdsha256.Size224, stdsha256.New224); err != nil {
+		t.Fatal(err)
+	}
+	sha224, err := hash.BuildFunction(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4")}
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(false, engine, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(rootHash), 56)
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree2, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	proof, err := merkle.ProofFrom(proofs[1].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(proof.Trail), 2)
+	assert.Assert(t, tree2.ValidateProof(proof, sha224([]byte("data2")), rootHash))
+
+	_, err = merkle.NewTree(merkle.NewTreeOptions(false, "unregistered", false))
+	assert.Error(t, err, "invalid engine: unregistered")
+}
+
+// TestPoseidonMerkleTree ...
+func TestPoseidonMerkleTree(t *testing.T) {
+	tree, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.POSEIDON_BN254, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var leaves hash.Hashes
+	for i := int64(1); i <= 4; i++ {
+		leaves = append(leaves, big.NewInt(i).FillBytes(make([]byte, 32)))
+	}
+	proofs, err := tree.AddLeaves(false, leaves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, _ := hash.Poseidon(big.NewInt(1), big.NewInt(2))
+	right, _ := hash.Poseidon(big.NewInt(3), big.NewInt(4))
+	root, _ := hash.Poseidon(left, right)
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, utls.ToHex(root.FillBytes(make([]byte, 32))))
+	assert.Assert(t, tree.ValidateProof(proofs[0], leaves[0], rootHash))
+
+	_, err = tree.AddLeaves(false, hash.BN254_SCALAR_FIELD.FillBytes(make([]byte, 32)))
+	assert.Error(t, err, "empty tree")
+
+	// Sources that aren't field elements can't be hashed
+	_, err = tree.AddLeaves(true, big.NewInt(1).FillBytes(make([]byte, 32)), []byte("not a field element"))
+	assert.Error(t, err, "invalid source at index 1: unable to hash it with poseidon-bn254")
+	_, err = tree.AddLeafReaders(strings.NewReader("not a field element"))
+	assert.Error(t, err, "invalid source at index 0: unable to hash it with poseidon-bn254")
+}
+
+// TestMultihashMerkleTree ...
+func TestMultihashMerkleTree(t *testing.T) {
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.Multihash = true
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, "1220e9e1bc4a10c502ef995ede1914b0186ed288b8dde80c8c533a0f93a96490f995")
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"multihash":true},"leaves":["12205b41362bc82b7f3d56edc5a306db22105707d01ff4819e26faef9724a2d406c9",`))
+
+	// The engine is found from the leaves
+	tree2, err := merkle.TreeFrom(strings.Replace(json, `"engine":"sha-256",`, "", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree2.GetEngine(), hash.SHA_256)
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	proof, err := merkle.ProofFrom(proofs[1].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, proof.Multihash)
+	assert.Equal(t, proof.Engine, hash.SHA_256)
+	assert.Equal(t, proof.String(), proofs[1].String())
+	assert.Assert(t, tree2.ValidateProof(proof, sha256([]byte("data2")), rootHash))
+
+	// A multihash proof that can't be stringified doesn't fall back to hexadecimal hashes
+	unencodable := *proof
+	unencodable.Engine = hash.POSEIDON_BN254
+	assert.Equal(t, unencodable.String(), "")
+	_, err = merkle.ProofFrom(unencodable.String())
+	assert.Error(t, err, "invalid proof: ")
+	assert.Assert(t, !tree2.ValidateProof(&unencodable, sha256([]byte("data2")), rootHash, true))
+
+	_, err = merkle.TreeFrom(strings.Replace(json, `"engine":"sha-256"`, `"engine":"sha-512"`, 1))
+	assert.Error(t, err, "invalid multihash: sha-256 leaf in a sha-512 tree")
+
+	options = merkle.NewTreeOptions(false, hash.POSEIDON_BN254, false)
+	options.Multihash = true
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid multihash: no code for poseidon-bn254")
+
+	options = merkle.NewTreeOptions(true, hash.SHA_256, false)
+	options.Multihash = true
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid multihash: no code for keyed, double-hashed or truncated trees")
+}
+
+// TestDomainSeparatedMerkleTree ...
+func TestDomainSeparatedMerkleTree(t *testing.T) {
+	// Test vectors from the Certificate Transparency reference implementation
+	data := []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+	roots := []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.DomainSeparation = true
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utls.ToHex(tree.HashLeaf(nil)), roots[0])
+	for i := 1; i < len(roots); i++ {
+		tree, err := merkle.NewTree(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources := [][]byte{}
+		for _, d := range data[:i+1] {
+			sources = append(sources, utls.Must(utls.FromHex(d)))
+		}
+		if _, err = tree.AddLeaves(true, sources...); err != nil {
+			t.Fatal(err)
+		}
+		rootHash, err := tree.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, rootHash, roots[i])
+	}
+
+	tree, err = merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, []byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := tree.HashLeaf([]byte("data1"))
+	assert.Equal(t, utls.ToHex(leaf), utls.ToHex(sha256(append([]byte{0x00}, []byte("data1")...))))
+	assert.Assert(t, proofs[0].DomainSeparation)
+	assert.Assert(t, tree.ValidateProof(proofs[0], leaf, rootHash))
+
+	// The mode is part of the proof and of the JSON representation
+	proof, err := merkle.ProofFrom(proofs[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, proof.DomainSeparation)
+	assert.Assert(t, tree.ValidateProof(proof, leaf, rootHash))
+	proof.DomainSeparation = false
+	assert.Assert(t, !tree.ValidateProof(proof, leaf, rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"domainSeparation":true},"leaves":["`+utls.ToHex(leaf)))
+	tree2, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	// A node can't be passed off as a leaf anymore
+	plain, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = plain.AddLeaves(true, []byte("data1"), []byte("data2"), []byte("data3"), []byte("data4")); err != nil {
+		t.Fatal(err)
+	}
+	plainRootHash, err := plain.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, plainRootHash != rootHash)
+}
+
+// TestSaltedMerkleTree ...
+func TestSaltedMerkleTree(t *testing.T) {
+	data := [][]byte{[]byte("yes"), []byte("no"), []byte("yes"), []byte("no")}
+	store := merkle.NewMemorySaltStore()
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.Salted = true
+	options.Salts = store
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Identical sources give distinct leaves that can't be guessed without their salt
+	assert.Equal(t, len(proofs[0].Salt), merkle.SALT_SIZE)
+	assert.Assert(t, utls.ToHex(proofs[0].Salt) != utls.ToHex(proofs[2].Salt))
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"salted":true},"leaves":[`))
+	assert.Assert(t, !strings.Contains(json, utls.ToHex(sha256([]byte("yes")))))
+	for _, proof := range proofs {
+		assert.Assert(t, !strings.Contains(json, utls.ToHex(proof.Salt)))
+	}
+
+	// The data owner only needs its source and its proof
+	proof, err := merkle.ProofFrom(proofs[2].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, proof.Salt, proofs[2].Salt)
+	leaf := tree.HashLeaf([]byte("yes"), proof.Salt)
+	assert.DeepEqual(t, leaf, sha256(append(append([]byte{}, proof.Salt...), []byte("yes")...)))
+	salt, found := store.Get(leaf)
+	assert.Assert(t, found)
+	assert.DeepEqual(t, salt, proof.Salt)
+
+	// A rebuilt tree needs the side store to issue salted proofs
+	rebuilt, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuiltRootHash, err := rebuilt.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rebuiltRootHash, rootHash)
+	p, found := rebuilt.GetProof(sha256(append(append([]byte{}, proofs[0].Salt...), []byte("yes")...)))
+	assert.Assert(t, found && p.Salt == nil)
+	rebuilt.SetSaltStore(store)
+	p, found = rebuilt.GetProof(sha256(append(append([]byte{}, proofs[0].Salt...), []byte("yes")...)))
+	assert.Assert(t, found)
+	assert.Equal(t, p.String(), proofs[0].String())
+
+	// Same with streamed sources
+	tree, err = merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readerProofs, err := tree.AddLeafReaders(strings.NewReader("yes"), strings.NewReader("no"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(readerProofs[0].Salt), merkle.SALT_SIZE)
+	readerLeaf := tree.HashLeaf([]byte("yes"), readerProofs[0].Salt)
+	p, found = tree.GetProof(readerLeaf)
+	assert.Assert(t, found)
+	assert.Equal(t, p.String(), readerProofs[0].String())
+}
+
+// TestTruncatedMerkleTree ...
+func TestTruncatedMerkleTree(t *testing.T) {
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.TruncatedSize = 20
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, []byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(rootHash), 40)
+
+	leaf := sha256([]byte("data1"))[:20]
+	assert.Equal(t, proofs[0].TruncatedSize, 20)
+	assert.Equal(t, len(proofs[0].Trail[0]), 20)
+	assert.Assert(t, tree.ValidateProof(proofs[0], leaf, rootHash))
+
+	proof, err := merkle.ProofFrom(proofs[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof.TruncatedSize, 20)
+	assert.Assert(t, tree.ValidateProof(proof, leaf, rootHash))
+	proof.TruncatedSize = 0
+	assert.Assert(t, !tree.ValidateProof(proof, leaf, rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"truncatedSize":20},"leaves":["`+utls.ToHex(leaf)+`",`))
+	tree2, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash2, err := tree2.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash2, rootHash)
+
+	// Full-size hashes aren't correct leaves of a truncated tree
+	_, err = tree2.AddLeaves(false, sha256([]byte("data1")))
+	assert.Error(t, err, "empty tree")
+
+	options.TruncatedSize = 8
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid digest size: 8 not in [16, 32] for sha-256")
+}
+
+// TestAppend ...
+func TestAppend(t *testing.T) {
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	tree, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.AddLeaves(true, data[:3]...); err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.Append(true, data[3:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(proofs), 2)
+	assert.Equal(t, tree.Size(), 5)
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, "e9e1bc4a10c502ef995ede1914b0186ed288b8dde80c8c533a0f93a96490f995")
+
+	expected, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedProofs, err := expected.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proofs[0].String(), expectedProofs[3].String())
+	assert.Equal(t, proofs[1].String(), expectedProofs[4].String())
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON, err := expected.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, json, expectedJSON)
+
+	// Appending one leaf at a time gives the same trees as building them at once, all proofs being valid
+	growing, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := hash.Hashes{}
+	for i := 1; i <= 17; i++ {
+		leaf := sha256([]byte{byte(i)})
+		leaves = append(leaves, leaf)
+		if _, err = growing.Append(false, leaf); err != nil && i > 1 {
+			t.Fatal(err)
+		}
+		rebuilt, err := merkle.NewTree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = rebuilt.AddLeaves(false, leaves...)
+		rootHash, err := growing.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rebuiltRootHash, err := rebuilt.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, rootHash, rebuiltRootHash)
+		for _, l := range leaves {
+			proof, found := growing.GetProof(l)
+			assert.Assert(t, found || i == 1)
+			assert.Assert(t, i == 1 || growing.ValidateProof(proof, l, rootHash))
+		}
+	}
+
+	// Adding leaves still replaces the existing ones
+	if _, err = tree.AddLeaves(true, data[:2]...); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree.Size(), 2)
+	depth, err := tree.Depth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, depth, 1)
+
+	sorted, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.SHA_256, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sorted.AddLeaves(true, data[:2]...); err != nil {
+		t.Fatal(err)
+	}
+	_, err = sorted.Append(true, data[2:]...)
+	assert.Error(t, err, "unable to append to a sorted tree")
+
+	_, err = tree.Append(false, []byte("123"))
+	assert.Error(t, err, "empty tree")
+}
+
+// TestUpdateLeaves ...
+func TestUpdateLeaves(t *testing.T) {
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	tree, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.AddLeaves(true, data...); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := sha256([]byte("updated"))
+	rootHash, proofs, err := tree.UpdateLeaf(4, updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedProofs, err := expected.AddLeaves(true, data[0], data[1], data[2], data[3], []byte("updated"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHash, err := expected.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, expectedRootHash)
+	assert.Equal(t, len(proofs), 1)
+	assert.Equal(t, proofs[0].String(), expectedProofs[4].String())
+	assert.Assert(t, tree.ValidateProof(proofs[0], updated, rootHash))
+
+	// Leaves sharing ancestors
+	rootHash, proofs, err = tree.UpdateLeaves(map[int]hash.Hash{
+		2: sha256([]byte("stock3")),
+		0: sha256([]byte("stock1")),
+		1: sha256([]byte("stock2")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = expected.AddLeaves(true, []byte("stock1"), []byte("stock2"), []byte("stock3"), data[3], []byte("updated"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHash, err = expected.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, expectedRootHash)
+	assert.Equal(t, len(proofs), 3)
+	assert.Assert(t, tree.ValidateProof(proofs[1], sha256([]byte("stock2")), rootHash))
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJSON, err := expected.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, json, expectedJSON)
+
+	_, _, err = tree.UpdateLeaf(5, updated)
+	assert.Error(t, err, "index out of range: 5")
+	_, _, err = tree.UpdateLeaf(0, []byte("123"))
+	assert.Error(t, err, "invalid leaf at index 0")
+
+	sorted, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.SHA_256, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sorted.AddLeaves(true, data...); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = sorted.UpdateLeaf(0, updated)
+	assert.Error(t, err, "unable to update a sorted tree")
+}
+
+// TestRemoveLeaf ...
+func TestRemoveLeaf(t *testing.T) {
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	tree, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tree.AddLeaves(true, data...); err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.RemoveLeaf(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree.Size(), 4)
+	expected, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = expected.AddLeaves(true, data[0], data[2], data[3], data[4]); err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHash, err := expected.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, expectedRootHash)
+	depth, err := tree.Depth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, depth, 2)
+	_, found := tree.GetProof(sha256([]byte("data2")))
+	assert.Assert(t, !found)
+	proof, found := tree.GetProof(sha256([]byte("data5")))
+	assert.Assert(t, found)
+	assert.Assert(t, tree.ValidateProof(proof, sha256([]byte("data5")), rootHash))
+
+	_, err = tree.RemoveLeaf(4)
+	assert.Error(t, err, "index out of range: 4")
+	for tree.Size() > 1 {
+		if _, err = tree.RemoveLeaf(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rootHash, err = tree.RemoveLeaf(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, "")
+	assert.Equal(t, tree.Size(), 0)
+	_, err = tree.GetRootHash()
+	assert.Error(t, err, "tree not built")
+}
+
+// TestTombstone ...
+func TestTombstone(t *testing.T) {
+	data := [][]byte{[]byte("data1"), []byte("data2"), []byte("data3"), []byte("data4"), []byte("data5")}
+	tree, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.Tombstone(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, tree.IsTombstoned(1))
+	assert.Assert(t, !tree.IsTombstoned(0))
+	assert.Equal(t, tree.Size(), 5)
+
+	expected, err := merkle.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero := make([]byte, 32)
+	if _, err = expected.AddLeaves(false, sha256(data[0]), zero, sha256(data[2]), sha256(data[3]), sha256(data[4])); err != nil {
+		t.Fatal(err)
+	}
+	expectedRootHash, err := expected.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, expectedRootHash)
+
+	// Other leaves keep their index and path
+	for i, d := range data {
+		if i == 1 {
+			continue
+		}
+		proof, found := tree.GetProof(sha256(d))
+		assert.Assert(t, found)
+		assert.Equal(t, proof.Path, proofs[i].Path)
+		assert.Assert(t, tree.ValidateProof(proof, sha256(d), rootHash))
+	}
+	_, found := tree.GetProof(sha256(data[1]))
+	assert.Assert(t, !found)
+
+	_, err = tree.Tombstone(5)
+	assert.Error(t, err, "index out of range: 5")
+}
+
+// TestOddNodePolicy ...
+func TestOddNodePolicy(t *testing.T) {
+	a, b, c := sha256([]byte("data1")), sha256([]byte("data2")), sha256([]byte("data3"))
+	concat := func(left, right []byte) []byte {
+		return append(append([]byte{}, left...), right...)
+	}
+
+	// Bitcoin style
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.OddNodePolicy = merkle.ODD_NODE_DUPLICATE
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(false, a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, utls.ToHex(sha256(concat(sha256(concat(a, b)), sha256(concat(c, c))))))
+	assert.Equal(t, proofs[2].Path, "01")
+	assert.DeepEqual(t, proofs[2].Trail[1], c)
+	assert.Assert(t, tree.ValidateProof(proofs[2], c, rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"oddNodePolicy":"duplicate"},`))
+	rebuilt, err := merkle.TreeFrom(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuiltRootHash, err := rebuilt.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rebuiltRootHash, rootHash)
+
+	// The unpaired node gets a sibling
+	d := sha256([]byte("data4"))
+	if _, err = tree.Append(false, d); err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err = tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, utls.ToHex(sha256(concat(sha256(concat(a, b)), sha256(concat(c, d))))))
+
+	// RFC 6962 style
+	var split func(leaves hash.Hashes) hash.Hash
+	split = func(leaves hash.Hashes) hash.Hash {
+		if len(leaves) == 1 {
+			return leaves[0]
+		}
+		k := 1
+		for k*2 < len(leaves) {
+			k *= 2
+		}
+		return sha256(concat(split(leaves[:k]), split(leaves[k:])))
+	}
+	options = merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.OddNodePolicy = merkle.ODD_NODE_SPLIT
+	leaves := hash.Hashes{}
+	for i := 1; i <= 20; i++ {
+		leaves = append(leaves, sha256([]byte{byte(i)}))
+		tree, err := merkle.NewTree(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tree.AddLeaves(false, leaves...)
+		rootHash, err := tree.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, rootHash, utls.ToHex(split(leaves)))
+	}
+
+	options.OddNodePolicy = "unknown"
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid odd node policy: unknown")
+}
+
+// TestSortedPairsMerkleTree ...
+func TestSortedPairsMerkleTree(t *testing.T) {
+	options := merkle.NewTreeOptions(false, hash.KECCAK_256, false)
+	options.SortedPairs = true
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	one := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000001"))
+	two := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000002"))
+	three := utls.Must(utls.FromHex("0000000000000000000000000000000000000000000000000000000000000003"))
+	proofs, err := tree.AddLeaves(false, two, one)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Solidity: keccak256(abi.encodePacked(uint256(1), uint256(2)))
+	assert.Equal(t, rootHash, "e90b7bceb6e7df5418fb78d8ee546e97c83a08bbccc01a0644d599ccd2a7c2e0")
+	assert.Equal(t, proofs[0].Path, "")
+	assert.Assert(t, tree.ValidateProof(proofs[0], two, rootHash))
+	assert.Assert(t, tree.ValidateProof(proofs[1], one, rootHash))
+
+	proofs, err = tree.Append(false, three)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err = tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := merkle.ProofFrom(proofs[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof.Path, "")
+	assert.Assert(t, tree.ValidateProof(proof, three, rootHash))
+
+	// Path-less proof string with the sorted flag
+	assert.Assert(t, proof.SortedPairs)
+	compact := base64.StdEncoding.EncodeToString([]byte(utls.ToHex(proof.Trail[0]) + "..keccak-256.3.sorted"))
+	assert.Equal(t, proof.String(), compact)
+	proof, err = merkle.ProofFrom(compact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof.Size, 3)
+	assert.Assert(t, tree.ValidateProof(proof, three, rootHash))
+	for _, wrong := range []string{".keccak-256.3", ".keccak-256.3.sorted", ".1.keccak-256.3.sorted", "..keccak-256.3.sorted=1"} {
+		_, err = merkle.ProofFrom(base64.StdEncoding.EncodeToString([]byte(utls.ToHex(proof.Trail[0]) + wrong)))
+		assert.Assert(t, err != nil, wrong)
+	}
+
+	// Without the flag, the hashes aren't combined as sorted pairs
+	unflagged := *proof
+	unflagged.SortedPairs = false
+	assert.Assert(t, !tree.ValidateProof(&unflagged, three, rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"keccak-256","sort":false,"sortedPairs":true},`))
+
+	// A path-less proof can't be used in a regular tree
+	regular, err := merkle.NewTree(merkle.NewTreeOptions(false, hash.KECCAK_256, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = regular.AddLeaves(false, one, two, three); err != nil {
+		t.Fatal(err)
+	}
+	regularRootHash, err := regular.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, !regular.ValidateProof(proof, three, regularRootHash))
+}
+
+// TestKAryMerkleTree ...
+func TestKAryMerkleTree(t *testing.T) {
+	concat := func(hashes ...[]byte) []byte {
+		all := []byte{}
+		for _, h := range hashes {
+			all = append(all, h...)
+		}
+		return all
+	}
+	leaves := hash.Hashes{}
+	for i := 0; i < 10; i++ {
+		leaves = append(leaves, sha256([]byte{byte(i)}))
+	}
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.Arity = 4
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(false, leaves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := tree.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := sha256(concat(sha256(concat(leaves[0:4]...)), sha256(concat(leaves[4:8]...)), sha256(concat(leaves[8:]...))))
+	assert.Equal(t, rootHash, utls.ToHex(expected))
+	depth, err := tree.Depth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, depth, 2)
+
+	// Leaf #9: its sibling #8 then nodes #0 and #1 of the upper level
+	assert.Equal(t, proofs[9].Path, "21")
+	assert.Equal(t, proofs[9].Arity, 4)
+	assert.Equal(t, len(proofs[9].Trail), 3)
+	proof, err := merkle.ProofFrom(proofs[9].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, proof.Arity, 4)
+	assert.Assert(t, tree.ValidateProof(proof, leaves[9], rootHash))
+	assert.Assert(t, !tree.ValidateProof(proof, leaves[8], rootHash))
+
+	json, err := tree.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, strings.HasPrefix(json, `{"options":{"doubleHash":false,"engine":"sha-256","sort":false,"arity":4},`))
+
+	rootHash, _, err = tree.UpdateLeaves(map[int]hash.Hash{1: leaves[9], 9: leaves[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves[1], leaves[9] = leaves[9], leaves[1]
+	expected = sha256(concat(sha256(concat(leaves[0:4]...)), sha256(concat(leaves[4:8]...)), sha256(concat(leaves[8:]...))))
+	assert.Equal(t, rootHash, utls.ToHex(expected))
+
+	// All proofs are valid whatever the size of the tree, including when growing it
+	for _, arity := range []int{3, 4, 16} {
+		options.Arity = arity
+		growing, err := merkle.NewTree(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves := hash.Hashes{}
+		for i := 0; i < 40; i++ {
+			leaf := sha256([]byte{byte(arity), byte(i)})
+			leaves = append(leaves, leaf)
+			_, _ = growing.Append(false, leaf)
+			rootHash, err := growing.GetRootHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			rebuilt, err := merkle.NewTree(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = rebuilt.AddLeaves(false, leaves...)
+			rebuiltRootHash, err := rebuilt.GetRootHash()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, rootHash, rebuiltRootHash)
+			for _, l := range leaves {
+				proof, found := growing.GetProof(l)
+				assert.Assert(t, i == 0 || (found && growing.ValidateProof(proof, l, rootHash)))
+			}
+		}
+	}
+
+	options.Arity = 17
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid arity: 17")
+	options.Arity = 4
+	options.SortedPairs = true
+	_, err = merkle.NewTree(options)
+	assert.Error(t, err, "invalid arity: 4-ary trees only promote unpaired nodes and can't sort pairs")
+}
+
+// TestErasedSalts ...
+func TestErasedSalts(t *testing.T) {
+	store := merkle.NewMemorySaltStore()
+	options := merkle.NewTreeOptions(false, hash.SHA_256, false)
+	options.Salted = true
+	options.Salts = store
+	tree, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs, err := tree.AddLeaves(true, []byte("alice"), []byte("bob"), []byte("carol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := tree.HashLeaf([]byte("alice"), proofs[0].Salt), tree.HashLeaf([]byte("bob"), proofs[1].Salt)
+
+	// The erased data can't be confirmed from an old proof anymore
+	if _, err = tree.Tombstone(0); err != nil {
+		t.Fatal(err)
+	}
+	_, found := store.Get(alice)
+	assert.Assert(t, !found)
+	if _, err = tree.RemoveLeaf(1); err != nil {
+		t.Fatal(err)
+	}
+	_, found = store.Get(bob)
+	assert.Assert(t, !found)
+	_, found = store.Get(tree.HashLeaf([]byte("carol"), proofs[2].Salt))
+	assert.Assert(t, found)
+
+	// The salt of a leaf that can't be tombstoned is kept
+	sortedStore := merkle.NewMemorySaltStore()
+	options = merkle.NewTreeOptions(false, hash.SHA_256, true)
+	options.Salted = true
+	options.Salts = sortedStore
+	sorted, err := merkle.NewTree(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortedProofs, err := sorted.AddLeaves(true, []byte("alice"), []byte("bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = sorted.Tombstone(0)
+	assert.Error(t, err, "unable to update a sorted tree")
+	rootHash, err := sorted.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, data := range [][]byte{[]byte("alice"), []byte("bob")} {
+		found = false
+		for _, p := range sortedProofs {
+			leaf := sorted.HashLeaf(data, p.Salt)
+			if proof, ok := sorted.GetProof(leaf); ok {
+				found = true
+				_, stored := sortedStore.Get(leaf)
+				assert.Assert(t, stored, i)
+				assert.DeepEqual(t, proof.Salt, p.Salt)
+				assert.Assert(t, sorted.ValidateProof(proof, leaf, rootHash))
+			}
+		}
+		assert.Assert(t, found, i)
+	}
+}
@@ -0,0 +1,210 @@
+package mmr
+
+import (
+	"bytes"
+	"fmt"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+//--- TYPES
+
+// MMR is a Merkle mountain range, ie. an append-only accumulator made of perfect binary trees (the mountains) whose peaks are bagged into a single root.
+// Appending a leaf only hashes the nodes it completes, and an inclusion proof up to the peak of its mountain remains valid forever,
+// only needing to be extended when this mountain is merged into a higher one (see `ExtendProof()`).
+type MMR struct {
+	engine       string
+	hashFunction hash.Function
+	nodes        hash.Hashes
+	leafCount    int
+}
+
+//--- METHODS
+
+// Append adds either sources (by passing `true` to the first parameter) or hashes after the existing leaves and returns their indices,
+// sources that the engine can't hash being rejected
+func (m *MMR) Append(doHash bool, data ...[]byte) (indices []int, err error) {
+	if len(data) == 0 {
+		err = fmt.Errorf("empty tree")
+		return
+	}
+	leaves := hash.Hashes{}
+	for i, d := range data {
+		if doHash {
+			h := m.hashFunction(d)
+			if !hash.IsCorrect(h, m.engine) {
+				err = fmt.Errorf("invalid source at index %d: unable to hash it with %s", i, m.engine)
+				return
+			}
+			leaves = append(leaves, h)
+		} else if hash.IsCorrect(d, m.engine) {
+			leaves = append(leaves, d)
+		} else {
+			err = fmt.Errorf("invalid leaf: %s", utls.ToHex(d))
+			return
+		}
+	}
+	for _, leaf := range leaves {
+		pos := len(m.nodes)
+		m.nodes = append(m.nodes, leaf)
+		for height := 0; isRightChild(pos, height); height++ {
+			// Merge with the left sibling
+			m.nodes = append(m.nodes, m.hashNode(m.nodes[pos-siblingOffset(height)], m.nodes[pos]))
+			pos++
+		}
+		indices = append(indices, m.leafCount)
+		m.leafCount++
+	}
+	return
+}
+
+// ExtendProof returns the passed proof extended to the current state of the range, ie. with the siblings from its former peak up to its current one
+// and the current peaks
+func (m *MMR) ExtendProof(p *Proof) (extended *Proof, err error) {
+	if p == nil || p.Size > m.leafCount || p.LeafIndex < 0 || p.LeafIndex >= p.Size || len(p.Siblings) != mountainHeight(p.LeafIndex, p.Size) {
+		err = exception.NewInvalidMerkleProofError("unable to extend proof")
+		return
+	}
+	pos, height := LeafIndexToPos(p.LeafIndex), 0
+	for range p.Siblings {
+		pos, height = parent(pos, height), height+1
+	}
+	siblings := append(hash.Hashes{}, p.Siblings...)
+	extended = m.proofFrom(pos, height, siblings)
+	extended.LeafIndex = p.LeafIndex
+	return
+}
+
+// GetEngine returns the name of the used hashing function
+func (m *MMR) GetEngine() string {
+	return m.engine
+}
+
+// GetProof returns the inclusion proof of the leaf at the passed index
+func (m *MMR) GetProof(index int) (p *Proof, err error) {
+	if index < 0 || index >= m.leafCount {
+		err = fmt.Errorf("index out of range: %d", index)
+		return
+	}
+	p = m.proofFrom(LeafIndexToPos(index), 0, hash.Hashes{})
+	p.LeafIndex = index
+	return
+}
+
+// GetRootHash returns the hexadecimal representation of the bagged peaks
+func (m *MMR) GetRootHash() (rootHash string, err error) {
+	if m.leafCount == 0 {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	rootHash = utls.ToHex(m.bag(m.Peaks()))
+	return
+}
+
+// Peaks returns the hashes of the current peaks, from left to right
+func (m *MMR) Peaks() (peaks hash.Hashes) {
+	for _, pos := range PeakPositions(m.leafCount) {
+		peaks = append(peaks, m.nodes[pos])
+	}
+	return
+}
+
+// Size returns the number of leaves
+func (m *MMR) Size() int {
+	return m.leafCount
+}
+
+// ValidateProof checks that the passed proof matches the passed leaf using the passed root hash
+func (m *MMR) ValidateProof(p *Proof, leaf hash.Hash, rootHash string) bool {
+	if p == nil || p.Engine != m.engine || p.LeafIndex < 0 || p.LeafIndex >= p.Size {
+		return false
+	}
+	pos, height, h := LeafIndexToPos(p.LeafIndex), 0, leaf
+	for _, sibling := range p.Siblings {
+		if isRightChild(pos, height) {
+			h = m.hashNode(sibling, h)
+		} else {
+			h = m.hashNode(h, sibling)
+		}
+		pos, height = parent(pos, height), height+1
+	}
+	peaks := PeakPositions(p.Size)
+	if len(peaks) != len(p.Peaks) {
+		return false
+	}
+	for i, peak := range peaks {
+		if peak == pos {
+			return bytes.Equal(h, p.Peaks[i]) && utls.ToHex(m.bag(p.Peaks)) == rootHash
+		}
+	}
+	return false
+}
+
+// For internal use only
+
+// bag folds the passed peaks from right to left, ie. H(peak1 || H(peak2 || ... H(peakN-1 || peakN)))
+func (m *MMR) bag(peaks hash.Hashes) hash.Hash {
+	if len(peaks) == 0 {
+		return nil
+	}
+	root := peaks[len(peaks)-1]
+	for i := len(peaks) - 2; i >= 0; i-- {
+		root = m.hashNode(peaks[i], root)
+	}
+	return root
+}
+
+func (m *MMR) hashNode(left, right hash.Hash) hash.Hash {
+	return m.hashFunction(append(append([]byte{}, left...), right...))
+}
+
+// proofFrom adds to the passed siblings those from the node at the passed position and height up to its peak
+func (m *MMR) proofFrom(pos, height int, siblings hash.Hashes) *Proof {
+	for {
+		if isRightChild(pos, height) {
+			siblings = append(siblings, m.nodes[pos-siblingOffset(height)])
+		} else if sibling := pos + siblingOffset(height); sibling < len(m.nodes) {
+			siblings = append(siblings, m.nodes[sibling])
+		} else {
+			break
+		}
+		pos, height = parent(pos, height), height+1
+	}
+	return &Proof{
+		Siblings: siblings,
+		Peaks:    m.Peaks(),
+		Size:     m.leafCount,
+		Engine:   m.engine,
+	}
+}
+
+//--- FUNCTIONS
+
+// NewMMR instantiates a new empty Merkle mountain range using the passed engine, SHA-256 by default
+func NewMMR(engine ...string) (m *MMR, err error) {
+	usingEngine := hash.SHA_256
+	if len(engine) == 1 && engine[0] != "" {
+		usingEngine = engine[0]
+	}
+	fn, err := hash.BuildFunction(usingEngine)
+	if err != nil {
+		return
+	}
+	m = &MMR{
+		engine:       usingEngine,
+		hashFunction: fn,
+		nodes:        hash.Hashes{},
+	}
+	return
+}
+
+//--- utility
+
+func parent(pos, height int) int {
+	if isRightChild(pos, height) {
+		return pos + 1
+	}
+	return pos + siblingOffset(height) + 1
+}
@@ -0,0 +1,143 @@
+package mmr_test
+
+import (
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/mmr"
+	"gotest.tools/assert"
+)
+
+var sha256, _ = hash.BuildFunction(hash.SHA_256)
+
+func concat(left, right []byte) []byte {
+	return append(append([]byte{}, left...), right...)
+}
+
+// TestMMR ...
+func TestMMR(t *testing.T) {
+	m, err := mmr.NewMMR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.GetRootHash()
+	assert.Error(t, err, "tree not built")
+
+	leaves := hash.Hashes{}
+	for i := 0; i < 3; i++ {
+		leaves = append(leaves, sha256([]byte{byte(i)}))
+	}
+	indices, err := m.Append(false, leaves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.DeepEqual(t, indices, []int{0, 1, 2})
+	rootHash, err := m.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, rootHash, utls.ToHex(sha256(concat(sha256(concat(leaves[0], leaves[1])), leaves[2]))))
+
+	// A proof given at size 3...
+	oldProof, err := m.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(oldProof.Siblings), 0)
+	assert.Assert(t, m.ValidateProof(oldProof, leaves[2], rootHash))
+	oldRootHash := rootHash
+
+	for i := 3; i < 11; i++ {
+		leaves = append(leaves, sha256([]byte{byte(i)}))
+		if _, err = m.Append(true, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+		rootHash, err = m.GetRootHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index, leaf := range leaves {
+			proof, err := m.GetProof(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Assert(t, m.ValidateProof(proof, leaf, rootHash))
+		}
+	}
+	assert.Equal(t, m.Size(), 11)
+	assert.Equal(t, len(m.Peaks()), 3)
+
+	// ... remains valid against its root and only needs the siblings up to the new peak afterwards
+	assert.Assert(t, m.ValidateProof(oldProof, leaves[2], oldRootHash))
+	assert.Assert(t, !m.ValidateProof(oldProof, leaves[2], rootHash))
+	extended, err := m.ExtendProof(oldProof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(extended.Siblings), 3)
+	assert.Equal(t, extended.Size, 11)
+	assert.Assert(t, m.ValidateProof(extended, leaves[2], rootHash))
+	current, err := m.GetProof(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, extended.String(), current.String())
+
+	// Malformed proofs can't be extended
+	_, err = m.ExtendProof(&mmr.Proof{LeafIndex: -1, Size: 3, Engine: m.GetEngine()})
+	assert.Error(t, err, "invalid proof: unable to extend proof")
+	tooLong := *oldProof
+	tooLong.Siblings = append(append(hash.Hashes{}, oldProof.Siblings...), leaves[0], leaves[1])
+	_, err = m.ExtendProof(&tooLong)
+	assert.Error(t, err, "invalid proof: unable to extend proof")
+
+	_, err = m.GetProof(11)
+	assert.Error(t, err, "index out of range: 11")
+	_, err = m.Append(false, []byte("123"))
+	assert.Error(t, err, "invalid leaf: 313233")
+
+	// Sources the engine can't hash are rejected rather than giving an empty root
+	poseidon, err := mmr.NewMMR(hash.POSEIDON_BN254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = poseidon.Append(true, hash.PoseidonEncode([]byte("hello")), []byte("hello"))
+	assert.Error(t, err, "invalid source at index 1: unable to hash it with poseidon-bn254")
+	assert.Equal(t, poseidon.Size(), 0)
+}
+
+// TestMMRProof ...
+func TestMMRProof(t *testing.T) {
+	m, err := mmr.NewMMR(hash.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Append(true, []byte("event1"), []byte("event2"), []byte("event3"), []byte("event4"), []byte("event5")); err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := m.GetRootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := m.GetProof(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := mmr.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.LeafIndex, 1)
+	assert.Equal(t, instance.Size, 5)
+	assert.Equal(t, instance.Engine, hash.SHA3_256)
+	assert.Equal(t, instance.String(), proof.String())
+	sha3, err := hash.BuildFunction(hash.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, m.ValidateProof(instance, sha3([]byte("event2")), rootHash))
+
+	_, err = mmr.ProofFrom("not-a-valid-proof")
+	assert.Error(t, err, "invalid proof: not-a-valid-proof")
+}
@@ -0,0 +1,69 @@
+package mmr
+
+import (
+	"math/bits"
+)
+
+// LeafIndexToPos returns the position of the leaf at the passed index, positions being the zero-based indices of the nodes in the order they're added
+func LeafIndexToPos(index int) int {
+	return 2*index - bits.OnesCount(uint(index))
+}
+
+// NodeCount returns the number of nodes of a Merkle mountain range with the passed number of leaves
+func NodeCount(leafCount int) int {
+	return 2*leafCount - bits.OnesCount(uint(leafCount))
+}
+
+// PeakPositions returns the positions of the peaks of a Merkle mountain range with the passed number of leaves, from left to right,
+// ie. from the highest mountain to the lowest
+func PeakPositions(leafCount int) (peaks []int) {
+	offset := 0
+	for height := bits.Len(uint(leafCount)) - 1; height >= 0; height-- {
+		if leafCount&(1<<height) != 0 {
+			size := 1<<(height+1) - 1
+			peaks = append(peaks, offset+size-1)
+			offset += size
+		}
+	}
+	return
+}
+
+// PosHeight returns the height of the node at the passed position, leaves being at height 0
+func PosHeight(pos int) int {
+	pos++
+	for !allOnes(pos) {
+		// Jump to the same height in the left mountain
+		pos -= 1<<(bits.Len(uint(pos))-1) - 1
+	}
+	return bits.Len(uint(pos)) - 1
+}
+
+//--- utility
+
+func allOnes(n int) bool {
+	return n != 0 && n&(n+1) == 0
+}
+
+// mountainHeight returns the height of the mountain holding the leaf at the passed index in a Merkle mountain range with the passed number of leaves
+func mountainHeight(index, leafCount int) int {
+	offset := 0
+	for height := bits.Len(uint(leafCount)) - 1; height >= 0; height-- {
+		if leafCount&(1<<height) != 0 {
+			offset += 1 << height
+			if index < offset {
+				return height
+			}
+		}
+	}
+	return -1
+}
+
+// isRightChild tells if the node at the passed position and height is the right child of its parent, ie. if the next position is higher
+func isRightChild(pos, height int) bool {
+	return PosHeight(pos+1) > height
+}
+
+// siblingOffset returns the distance between two siblings at the passed height
+func siblingOffset(height int) int {
+	return 1<<(height+1) - 1
+}
@@ -0,0 +1,34 @@
+package mmr_test
+
+import (
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/mmr"
+	"gotest.tools/assert"
+)
+
+// TestPositions ...
+func TestPositions(t *testing.T) {
+	//          14
+	//        /    \
+	//       6      13
+	//      / \    /  \
+	//     2   5  9    12    17
+	//    / \ / \ / \  / \   / \
+	//   0  1 3 4 7 8 10 11 15 16 18
+	for index, pos := range []int{0, 1, 3, 4, 7, 8, 10, 11, 15, 16, 18} {
+		assert.Equal(t, mmr.LeafIndexToPos(index), pos)
+		assert.Equal(t, mmr.PosHeight(pos), 0)
+	}
+	assert.Equal(t, mmr.PosHeight(2), 1)
+	assert.Equal(t, mmr.PosHeight(12), 1)
+	assert.Equal(t, mmr.PosHeight(17), 1)
+	assert.Equal(t, mmr.PosHeight(13), 2)
+	assert.Equal(t, mmr.PosHeight(14), 3)
+
+	assert.Equal(t, mmr.NodeCount(4), 7)
+	assert.Equal(t, mmr.NodeCount(11), 19)
+	assert.DeepEqual(t, mmr.PeakPositions(11), []int{14, 17, 18})
+	assert.DeepEqual(t, mmr.PeakPositions(8), []int{14})
+	assert.Equal(t, len(mmr.PeakPositions(0)), 0)
+}
@@ -0,0 +1,89 @@
+package mmr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// Proof defines the inclusion proof of a leaf in a Merkle mountain range of the passed size (its number of leaves) made of the siblings
+// from the leaf up to the peak of its mountain, bottom-up, and of all the peaks of the range to bag
+type Proof struct {
+	LeafIndex int
+	Siblings  hash.Hashes
+	Peaks     hash.Hashes
+	Size      int
+	Engine    string
+}
+
+// String returns the base64-encoded dot-separated concatenation of the hexadecimal siblings and peaks, the index of the leaf,
+// the size of the range and the engine, eg. Base64Encode("<sibling1><sibling2>.<peak1><peak2>.3.7.sha-256")
+func (p *Proof) String() string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s.%s.%d.%d.%s", toHex(p.Siblings), toHex(p.Peaks), p.LeafIndex, p.Size, p.Engine)))
+}
+
+// ProofFrom builds a Merkle mountain range proof from the passed string, provided it's an actual stringified proof
+func ProofFrom(b64 string) (p *Proof, err error) {
+	str, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	parts := strings.Split(string(str), ".")
+	if len(parts) != 5 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	digestSize, e1 := hash.GetDigestSize(parts[4])
+	leafIndex, e2 := strconv.Atoi(parts[2])
+	size, e3 := strconv.Atoi(parts[3])
+	if e1 != nil || e2 != nil || e3 != nil || leafIndex < 0 || leafIndex >= size {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	siblings, e1 := fromHex(parts[0], digestSize)
+	peaks, e2 := fromHex(parts[1], digestSize)
+	if e1 != nil || e2 != nil || len(peaks) == 0 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	p = &Proof{
+		LeafIndex: leafIndex,
+		Siblings:  siblings,
+		Peaks:     peaks,
+		Size:      size,
+		Engine:    parts[4],
+	}
+	return
+}
+
+//--- utility
+
+func fromHex(str string, digestSize int) (hashes hash.Hashes, err error) {
+	b, err := utls.FromHex(str)
+	if err != nil {
+		return
+	}
+	if len(b)%digestSize != 0 {
+		err = fmt.Errorf("wrong length")
+		return
+	}
+	hashes = hash.Hashes{}
+	for i := 0; i < len(b); i += digestSize {
+		hashes = append(hashes, b[i:i+digestSize])
+	}
+	return
+}
+
+func toHex(hashes hash.Hashes) string {
+	var str []string
+	for _, h := range hashes {
+		str = append(str, utls.ToHex(h))
+	}
+	return strings.Join(str, "")
+}
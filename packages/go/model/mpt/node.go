@@ -0,0 +1,111 @@
+package mpt
+
+// node is either nil (the empty node), a *leafNode, an *extensionNode or a *branchNode
+type node interface{}
+
+// leafNode terminates the path with the remaining nibbles of the key
+type leafNode struct {
+	key   []byte
+	value []byte
+}
+
+// extensionNode shares the nibbles of its key among all the keys below its child, which is always a branch
+type extensionNode struct {
+	key   []byte
+	child node
+}
+
+// branchNode has one child per nibble and holds the value of the key ending at it, if any
+type branchNode struct {
+	children [16]node
+	value    []byte
+}
+
+//--- METHODS
+
+// set places the passed value at the passed remaining nibbles below the branch, which must be free
+func (b *branchNode) set(nibbles, value []byte) {
+	if len(nibbles) == 0 {
+		b.value = value
+	} else {
+		b.children[nibbles[0]] = &leafNode{key: nibbles[1:], value: value}
+	}
+}
+
+// collapse returns the node replacing the branch when it's left with a single child or value
+func (b *branchNode) collapse() node {
+	index, count := -1, 0
+	for i, child := range b.children {
+		if child != nil {
+			index = i
+			count++
+		}
+	}
+	switch {
+	case count == 0 && b.value != nil:
+		return &leafNode{key: []byte{}, value: b.value}
+	case count == 1 && b.value == nil:
+		return withPrefix([]byte{byte(index)}, b.children[index])
+	default:
+		return b
+	}
+}
+
+//--- utility
+
+// compact returns the hex-prefix encoding of the passed nibbles, the flag telling whether they belong to a leaf or an extension
+func compact(nibbles []byte, isLeaf bool) []byte {
+	flag := byte(0)
+	if isLeaf {
+		flag = 2
+	}
+	if len(nibbles)%2 == 1 {
+		nibbles = append([]byte{flag + 1}, nibbles...)
+	} else {
+		nibbles = append([]byte{flag, 0}, nibbles...)
+	}
+	b := make([]byte, len(nibbles)/2)
+	for i := range b {
+		b[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+	}
+	return b
+}
+
+// decompact is the reverse of compact
+func decompact(b []byte) (nibbles []byte, isLeaf bool, ok bool) {
+	if len(b) == 0 || b[0]>>4 > 3 {
+		return
+	}
+	flag := b[0] >> 4
+	isLeaf = flag >= 2
+	all := toNibbles(b)
+	if flag%2 == 1 {
+		nibbles = all[1:]
+	} else if all[1] == 0 {
+		nibbles = all[2:]
+	} else {
+		return
+	}
+	ok = true
+	return
+}
+
+func toNibbles(key []byte) []byte {
+	nibbles := make([]byte, len(key)*2)
+	for i, b := range key {
+		nibbles[2*i] = b >> 4
+		nibbles[2*i+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+func commonPrefixLength(a, b []byte) (i int) {
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return
+}
+
+func concatNibbles(a, b []byte) []byte {
+	return append(append([]byte{}, a...), b...)
+}
@@ -0,0 +1,155 @@
+package mpt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// Proof is the list of the RLP-encoded nodes on the path to a key, starting with the root node, as returned by `eth_getProof`
+type Proof [][]byte
+
+// String returns the base64-encoded dot-separated concatenation of the hexadecimal nodes, eg. Base64Encode("<node1>.<node2>.<node3>")
+func (p Proof) String() string {
+	var str []string
+	for _, n := range p {
+		str = append(str, utls.ToHex(n))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(str, ".")))
+}
+
+// ProofFrom builds a Merkle Patricia Trie proof from the passed string, provided it's an actual stringified proof,
+// an empty string being the empty proof of an empty trie
+func ProofFrom(b64 string) (p Proof, err error) {
+	str, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	if len(str) == 0 {
+		p = Proof{}
+		return
+	}
+	if p, err = ProofFromNodes(strings.Split(string(str), ".")...); err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+	}
+	return
+}
+
+// ProofFromNodes builds a Merkle Patricia Trie proof from the passed hexadecimal nodes, with or without the "0x" prefix,
+// eg. the `accountProof` or `storageProof[i].proof` items of an `eth_getProof` response
+func ProofFromNodes(nodes ...string) (p Proof, err error) {
+	for _, n := range nodes {
+		b, e := utls.FromHex(strings.TrimPrefix(n, "0x"))
+		if e != nil || len(b) == 0 {
+			err = exception.NewInvalidMerkleProofError(n)
+			return
+		}
+		p = append(p, b)
+	}
+	return
+}
+
+// VerifyProof checks the passed proof against the passed hexadecimal root hash and returns the value stored at the passed key,
+// which is empty if the proof proves the key is absent from the trie.
+// Any key is absent from an empty trie, whose `EMPTY_ROOT` needs no proof, eg. the empty `storageProof[i].proof` returned by `eth_getProof`
+// for an account without storage.
+// NB: for an Ethereum account, the key is Keccak-256(address) and the value is RLP([nonce, balance, storageRoot, codeHash]).
+func VerifyProof(rootHash string, key []byte, p Proof) (value []byte, err error) {
+	keccak, err := hash.BuildFunction(hash.KECCAK_256)
+	if err != nil {
+		return
+	}
+	nodes := make(map[string][]byte)
+	for _, n := range p {
+		nodes[utls.ToHex(keccak(n))] = n
+	}
+	wanted, nibbles := strings.ToLower(strings.TrimPrefix(rootHash, "0x")), toNibbles(key)
+	if wanted == EMPTY_ROOT {
+		return
+	}
+	for {
+		encoded, found := nodes[wanted]
+		if !found {
+			err = exception.NewInvalidMerkleProofError("missing node " + wanted)
+			return
+		}
+		item, e := RLPDecode(encoded)
+		if e != nil {
+			err = exception.NewInvalidMerkleProofError(e.Error())
+			return
+		}
+		// Walk down the embedded nodes until reaching a hash reference or the end of the path
+		for {
+			var child *RLPItem
+			child, nibbles, value, err = step(item, nibbles)
+			if err != nil || child == nil {
+				return
+			}
+			if !child.IsList {
+				if len(child.Bytes) == 0 {
+					// Empty child, ie. absent key
+					return
+				}
+				if len(child.Bytes) != 32 {
+					err = exception.NewInvalidMerkleProofError("invalid node reference")
+					return
+				}
+				wanted = utls.ToHex(child.Bytes)
+				break
+			}
+			item = child
+		}
+	}
+}
+
+//--- utility
+
+// step follows the passed nibbles through the passed decoded node, returning either the child to go on with or the final value
+func step(item *RLPItem, nibbles []byte) (child *RLPItem, rest []byte, value []byte, err error) {
+	if !item.IsList {
+		err = exception.NewInvalidMerkleProofError("invalid node")
+		return
+	}
+	switch len(item.List) {
+	case 17:
+		if len(nibbles) == 0 {
+			if item.List[16].IsList {
+				err = exception.NewInvalidMerkleProofError("invalid branch value")
+				return
+			}
+			value = item.List[16].Bytes
+			return
+		}
+		child, rest = item.List[nibbles[0]], nibbles[1:]
+		return
+	case 2:
+		if item.List[0].IsList {
+			err = exception.NewInvalidMerkleProofError("invalid node key")
+			return
+		}
+		key, isLeaf, ok := decompact(item.List[0].Bytes)
+		if !ok {
+			err = exception.NewInvalidMerkleProofError("invalid node key")
+			return
+		}
+		if isLeaf {
+			if bytes.Equal(key, nibbles) && !item.List[1].IsList {
+				value = item.List[1].Bytes
+			}
+			return
+		}
+		if commonPrefixLength(key, nibbles) < len(key) {
+			return
+		}
+		child, rest = item.List[1], nibbles[len(key):]
+		return
+	default:
+		err = exception.NewInvalidMerkleProofError("invalid node")
+		return
+	}
+}
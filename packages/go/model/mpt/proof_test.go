@@ -0,0 +1,89 @@
+package mpt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/mpt"
+	"gotest.tools/assert"
+)
+
+// TestProof ...
+func TestProof(t *testing.T) {
+	trie, _ := mpt.NewTrie()
+	for i := 0; i < 100; i++ {
+		trie.Insert([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	rootHash := trie.GetRootHash()
+
+	for _, i := range []int{0, 7, 42, 99} {
+		key := []byte(fmt.Sprintf("key%d", i))
+		proof, err := trie.GetProof(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := mpt.VerifyProof(rootHash, key, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(value), fmt.Sprintf("value%d", i))
+
+		// Back and forth through strings
+		fromString, err := mpt.ProofFrom(proof.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.DeepEqual(t, fromString, proof)
+	}
+
+	// Exclusion proof
+	proof, _ := trie.GetProof([]byte("key100"))
+	value, err := mpt.VerifyProof(rootHash, []byte("key100"), proof)
+	assert.NilError(t, err)
+	assert.Equal(t, len(value), 0)
+
+	// Wrong root or tampered proof
+	proof, _ = trie.GetProof([]byte("key42"))
+	_, err = mpt.VerifyProof(strings.Repeat("00", 32), []byte("key42"), proof)
+	assert.ErrorContains(t, err, "invalid proof: missing node")
+	_, err = mpt.VerifyProof(rootHash, []byte("key42"), proof[:len(proof)-1])
+	assert.ErrorContains(t, err, "invalid proof: missing node")
+
+	// Small trie with an embedded root
+	small, _ := mpt.NewTrie()
+	small.Insert([]byte("A"), []byte("a"))
+	proof, _ = small.GetProof([]byte("A"))
+	value, err = mpt.VerifyProof(small.GetRootHash(), []byte("A"), proof)
+	assert.NilError(t, err)
+	assert.Equal(t, string(value), "a")
+
+	// From eth_getProof-like hexadecimal nodes
+	var nodes []string
+	for _, n := range proof {
+		nodes = append(nodes, fmt.Sprintf("0x%x", n))
+	}
+	fromNodes, err := mpt.ProofFromNodes(nodes...)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fromNodes, proof)
+}
+
+// TestEmptyTrieProof ...
+func TestEmptyTrieProof(t *testing.T) {
+	trie, _ := mpt.NewTrie()
+	proof, err := trie.GetProof([]byte("key"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(proof), 0)
+	fromString, err := mpt.ProofFrom(proof.String())
+	assert.NilError(t, err)
+	assert.Equal(t, len(fromString), 0)
+
+	// eth_getProof returns the empty root and no node for every storage slot of an account without storage
+	fromNodes, err := mpt.ProofFromNodes()
+	assert.NilError(t, err)
+	for _, root := range []string{mpt.EMPTY_ROOT, "0x" + mpt.EMPTY_ROOT} {
+		value, err := mpt.VerifyProof(root, []byte("key"), fromNodes)
+		assert.NilError(t, err)
+		assert.Equal(t, len(value), 0)
+	}
+}
@@ -0,0 +1,129 @@
+package mpt
+
+import (
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+)
+
+//--- TYPES
+
+// RLPItem is a decoded RLP item, ie. either a byte string or a list of items
+type RLPItem struct {
+	IsList bool
+	Bytes  []byte
+	List   []*RLPItem
+
+	// Raw holds the whole encoding of the item
+	Raw []byte
+}
+
+//--- FUNCTIONS
+
+// RLPEncodeBytes returns the RLP encoding of the passed byte string
+func RLPEncodeBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+// RLPEncodeList returns the RLP encoding of the list of the passed items, each one being already RLP-encoded
+func RLPEncodeList(items ...[]byte) []byte {
+	payload := []byte{}
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+// RLPDecode decodes the passed RLP encoding of a single item
+func RLPDecode(b []byte) (item *RLPItem, err error) {
+	item, rest, err := rlpDecode(b)
+	if err == nil && len(rest) != 0 {
+		err = exception.NewInvalidRLPError("trailing bytes")
+	}
+	return
+}
+
+//--- utility
+
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	lengthBytes := []byte{}
+	for l := length; l > 0; l >>= 8 {
+		lengthBytes = append([]byte{byte(l)}, lengthBytes...)
+	}
+	return append([]byte{offset + 55 + byte(len(lengthBytes))}, lengthBytes...)
+}
+
+func rlpDecode(b []byte) (item *RLPItem, rest []byte, err error) {
+	if len(b) == 0 {
+		err = exception.NewInvalidRLPError("empty input")
+		return
+	}
+	prefix := b[0]
+	var offset, length int
+	switch {
+	case prefix < 0x80:
+		item = &RLPItem{Bytes: b[:1], Raw: b[:1]}
+		rest = b[1:]
+		return
+	case prefix < 0xb8:
+		offset, length = 1, int(prefix-0x80)
+	case prefix < 0xc0:
+		offset, length, err = rlpLongLength(b, int(prefix-0xb7))
+	case prefix < 0xf8:
+		offset, length = 1, int(prefix-0xc0)
+	default:
+		offset, length, err = rlpLongLength(b, int(prefix-0xf7))
+	}
+	if err != nil {
+		return
+	}
+	if length > len(b)-offset {
+		err = exception.NewInvalidRLPError("truncated input")
+		return
+	}
+	payload := b[offset : offset+length]
+	item = &RLPItem{Raw: b[:offset+length]}
+	rest = b[offset+length:]
+	if prefix < 0xc0 {
+		if length == 1 && payload[0] < 0x80 {
+			err = exception.NewInvalidRLPError("non-canonical single byte")
+			return
+		}
+		item.Bytes = payload
+		return
+	}
+	item.IsList = true
+	for len(payload) > 0 {
+		child, r, e := rlpDecode(payload)
+		if e != nil {
+			err = e
+			return
+		}
+		item.List = append(item.List, child)
+		payload = r
+	}
+	return
+}
+
+func rlpLongLength(b []byte, lengthSize int) (offset, length int, err error) {
+	if lengthSize > 8 || len(b) < 1+lengthSize || b[1] == 0 {
+		err = exception.NewInvalidRLPError("invalid length")
+		return
+	}
+	for _, l := range b[1 : 1+lengthSize] {
+		length = length<<8 | int(l)
+	}
+	if length < 0 || length > len(b)-1-lengthSize {
+		err = exception.NewInvalidRLPError("truncated input")
+		return
+	}
+	if length < 56 {
+		err = exception.NewInvalidRLPError("non-canonical length")
+		return
+	}
+	return 1 + lengthSize, length, nil
+}
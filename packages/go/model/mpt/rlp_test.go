@@ -0,0 +1,44 @@
+package mpt_test
+
+import (
+	"bytes"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/mpt"
+	"gotest.tools/assert"
+)
+
+// TestRLP ...
+func TestRLP(t *testing.T) {
+	// Examples from the Ethereum yellow paper and documentation
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeBytes([]byte("dog"))), "83646f67")
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeBytes(nil)), "80")
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeBytes([]byte{0x0f})), "0f")
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeBytes([]byte{0x04, 0x00})), "820400")
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeList()), "c0")
+	assert.Equal(t, utls.ToHex(mpt.RLPEncodeList(mpt.RLPEncodeBytes([]byte("cat")), mpt.RLPEncodeBytes([]byte("dog")))), "c88363617483646f67")
+	lorem := []byte("Lorem ipsum dolor sit amet, consectetur adipisicing elit")
+	encoded := mpt.RLPEncodeBytes(lorem)
+	assert.Equal(t, utls.ToHex(encoded[:2]), "b838")
+
+	item, err := mpt.RLPDecode(encoded)
+	assert.NilError(t, err)
+	assert.Assert(t, !item.IsList && bytes.Equal(item.Bytes, lorem))
+	item, err = mpt.RLPDecode(utls.Must(utls.FromHex("c88363617483646f67")))
+	assert.NilError(t, err)
+	assert.Assert(t, item.IsList)
+	assert.Equal(t, len(item.List), 2)
+	assert.Equal(t, string(item.List[1].Bytes), "dog")
+
+	_, err = mpt.RLPDecode(utls.Must(utls.FromHex("8100")))
+	assert.Error(t, err, "invalid RLP: non-canonical single byte")
+	_, err = mpt.RLPDecode(utls.Must(utls.FromHex("83646f")))
+	assert.Error(t, err, "invalid RLP: truncated input")
+	_, err = mpt.RLPDecode(utls.Must(utls.FromHex("bf7fffffffffffffff")))
+	assert.Error(t, err, "invalid RLP: truncated input")
+	_, err = mpt.RLPDecode(utls.Must(utls.FromHex("ff7fffffffffffffff00")))
+	assert.Error(t, err, "invalid RLP: truncated input")
+	_, err = mpt.RLPDecode(utls.Must(utls.FromHex("83646f6700")))
+	assert.Error(t, err, "invalid RLP: trailing bytes")
+}
@@ -0,0 +1,248 @@
+package mpt
+
+import (
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// EMPTY_ROOT is the hexadecimal root hash of an empty trie, ie. Keccak-256(RLP(""))
+const EMPTY_ROOT = "56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"
+
+//--- TYPES
+
+// Trie is a Merkle Patricia Trie as used by Ethereum for its state, storage, transactions and receipts:
+// keys are split into nibbles along branch, extension and leaf nodes, each node being RLP-encoded and referenced
+// by its Keccak-256 hash, or embedded in its parent if its encoding is shorter than 32 bytes.
+// NB: the trie stores keys as passed, so that a "secure" trie such as Ethereum's state trie expects callers to pass Keccak-256(key).
+type Trie struct {
+	root         node
+	hashFunction hash.Function
+	size         int
+}
+
+//--- METHODS
+
+// Delete removes the passed key from the trie, returning whether it was there
+func (t *Trie) Delete(key []byte) (deleted bool) {
+	t.root, deleted = remove(t.root, toNibbles(key))
+	if deleted {
+		t.size--
+	}
+	return
+}
+
+// Get returns the value stored at the passed key, if any
+func (t *Trie) Get(key []byte) (value []byte, found bool) {
+	n, nibbles := t.root, toNibbles(key)
+	for {
+		switch current := n.(type) {
+		case *leafNode:
+			if string(current.key) == string(nibbles) {
+				return current.value, true
+			}
+			return nil, false
+		case *extensionNode:
+			if commonPrefixLength(current.key, nibbles) < len(current.key) {
+				return nil, false
+			}
+			n, nibbles = current.child, nibbles[len(current.key):]
+		case *branchNode:
+			if len(nibbles) == 0 {
+				return current.value, current.value != nil
+			}
+			n, nibbles = current.children[nibbles[0]], nibbles[1:]
+		default:
+			return nil, false
+		}
+	}
+}
+
+// GetProof returns the list of the RLP-encoded nodes from the root down to the passed key, ie. the format used by `eth_getProof`:
+// the key needs not be in the trie, the proof then being an exclusion proof, which is empty for an empty trie
+func (t *Trie) GetProof(key []byte) (p Proof, err error) {
+	if t.root == nil {
+		p = Proof{}
+		return
+	}
+	n, nibbles := t.root, toNibbles(key)
+	p = Proof{t.encode(n)}
+	for {
+		switch current := n.(type) {
+		case *extensionNode:
+			if commonPrefixLength(current.key, nibbles) < len(current.key) {
+				return
+			}
+			n, nibbles = current.child, nibbles[len(current.key):]
+		case *branchNode:
+			if len(nibbles) == 0 {
+				return
+			}
+			n, nibbles = current.children[nibbles[0]], nibbles[1:]
+		default:
+			return
+		}
+		if n == nil {
+			return
+		}
+		// Embedded nodes are already part of their parent's encoding
+		if encoded := t.encode(n); len(encoded) >= 32 {
+			p = append(p, encoded)
+		}
+	}
+}
+
+// GetRootHash returns the hexadecimal Keccak-256 hash of the RLP-encoded root node
+func (t *Trie) GetRootHash() string {
+	return utls.ToHex(t.hashFunction(t.encode(t.root)))
+}
+
+// Insert sets the passed value at the passed key, an empty value deleting the key as Ethereum does
+func (t *Trie) Insert(key, value []byte) {
+	if len(value) == 0 {
+		t.Delete(key)
+		return
+	}
+	var inserted bool
+	t.root, inserted = insert(t.root, toNibbles(key), append([]byte{}, value...))
+	if inserted {
+		t.size++
+	}
+}
+
+// Size returns the number of keys in the trie
+func (t *Trie) Size() int {
+	return t.size
+}
+
+// For internal use only
+
+// encode returns the RLP encoding of the passed node
+func (t *Trie) encode(n node) []byte {
+	switch current := n.(type) {
+	case *leafNode:
+		return RLPEncodeList(RLPEncodeBytes(compact(current.key, true)), RLPEncodeBytes(current.value))
+	case *extensionNode:
+		return RLPEncodeList(RLPEncodeBytes(compact(current.key, false)), t.reference(current.child))
+	case *branchNode:
+		items := make([][]byte, 17)
+		for i, child := range current.children {
+			items[i] = t.reference(child)
+		}
+		items[16] = RLPEncodeBytes(current.value)
+		return RLPEncodeList(items...)
+	default:
+		return RLPEncodeBytes(nil)
+	}
+}
+
+// reference returns the RLP item standing for the passed child node in its parent, ie. either its encoding or the encoded hash of it
+func (t *Trie) reference(n node) []byte {
+	encoded := t.encode(n)
+	if n == nil || len(encoded) < 32 {
+		return encoded
+	}
+	return RLPEncodeBytes(t.hashFunction(encoded))
+}
+
+//--- FUNCTIONS
+
+// NewTrie instantiates a new empty Merkle Patricia Trie
+func NewTrie() (t *Trie, err error) {
+	fn, err := hash.BuildFunction(hash.KECCAK_256)
+	if err != nil {
+		return
+	}
+	t = &Trie{
+		hashFunction: fn,
+	}
+	return
+}
+
+//--- utility
+
+func insert(n node, nibbles, value []byte) (node, bool) {
+	switch current := n.(type) {
+	case *leafNode:
+		p := commonPrefixLength(current.key, nibbles)
+		if p == len(current.key) && p == len(nibbles) {
+			current.value = value
+			return current, false
+		}
+		branch := &branchNode{}
+		branch.set(current.key[p:], current.value)
+		branch.set(nibbles[p:], value)
+		return withPrefix(nibbles[:p], branch), true
+	case *extensionNode:
+		p := commonPrefixLength(current.key, nibbles)
+		if p == len(current.key) {
+			var inserted bool
+			current.child, inserted = insert(current.child, nibbles[p:], value)
+			return current, inserted
+		}
+		branch := &branchNode{}
+		branch.children[current.key[p]] = withPrefix(current.key[p+1:], current.child)
+		branch.set(nibbles[p:], value)
+		return withPrefix(nibbles[:p], branch), true
+	case *branchNode:
+		if len(nibbles) == 0 {
+			inserted := current.value == nil
+			current.value = value
+			return current, inserted
+		}
+		var inserted bool
+		current.children[nibbles[0]], inserted = insert(current.children[nibbles[0]], nibbles[1:], value)
+		return current, inserted
+	default:
+		return &leafNode{key: nibbles, value: value}, true
+	}
+}
+
+func remove(n node, nibbles []byte) (node, bool) {
+	switch current := n.(type) {
+	case *leafNode:
+		if string(current.key) == string(nibbles) {
+			return nil, true
+		}
+		return current, false
+	case *extensionNode:
+		if commonPrefixLength(current.key, nibbles) < len(current.key) {
+			return current, false
+		}
+		child, deleted := remove(current.child, nibbles[len(current.key):])
+		if !deleted {
+			return current, false
+		}
+		return withPrefix(current.key, child), true
+	case *branchNode:
+		var deleted bool
+		if len(nibbles) == 0 {
+			deleted = current.value != nil
+			current.value = nil
+		} else {
+			current.children[nibbles[0]], deleted = remove(current.children[nibbles[0]], nibbles[1:])
+		}
+		if !deleted {
+			return current, false
+		}
+		return current.collapse(), true
+	default:
+		return nil, false
+	}
+}
+
+// withPrefix prepends the passed nibbles to the path of the passed node, merging them with its own key when it's a leaf or an extension
+func withPrefix(prefix []byte, n node) node {
+	if len(prefix) == 0 {
+		return n
+	}
+	switch current := n.(type) {
+	case *leafNode:
+		return &leafNode{key: concatNibbles(prefix, current.key), value: current.value}
+	case *extensionNode:
+		return &extensionNode{key: concatNibbles(prefix, current.key), child: current.child}
+	case nil:
+		return nil
+	default:
+		return &extensionNode{key: prefix, child: n}
+	}
+}
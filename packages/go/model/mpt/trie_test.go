@@ -0,0 +1,62 @@
+package mpt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/mpt"
+	"gotest.tools/assert"
+)
+
+// TestTrie ...
+func TestTrie(t *testing.T) {
+	// Vectors from the official Ethereum trie tests (https://github.com/ethereum/tests/tree/develop/TrieTests)
+	vectors := []struct {
+		name     string
+		inputs   [][2]string
+		rootHash string
+	}{
+		{"emptyTrie", nil, mpt.EMPTY_ROOT},
+		{"singleItem", [][2]string{{"A", strings.Repeat("a", 50)}}, "d23786fb4a010da3ce639d66d5e904a11dbc02746d1ce25029e53290cabf28ab"},
+		{"dogs", [][2]string{{"doe", "reindeer"}, {"dog", "puppy"}, {"dogglesworth", "cat"}}, "8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3"},
+		{"puppy", [][2]string{{"do", "verb"}, {"horse", "stallion"}, {"doge", "coin"}, {"dog", "puppy"}}, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"},
+		{"foo", [][2]string{{"foo", "bar"}, {"food", "bass"}}, "17beaa1648bafa633cda809c90c04af50fc8aed3cb40d16efbddee6fdf63c4c3"},
+		{"smallValues", [][2]string{{"be", "e"}, {"dog", "puppy"}, {"bed", "d"}}, "3f67c7a47520f79faa29255d2d3c084a7a6df0453116ed7232ff10277a8be68b"},
+		{"emptyValues", [][2]string{{"do", "verb"}, {"ether", "wookiedoo"}, {"horse", "stallion"}, {"shaman", "horse"}, {"doge", "coin"}, {"ether", ""}, {"dog", "puppy"}, {"shaman", ""}}, "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"},
+	}
+	for _, vector := range vectors {
+		trie, err := mpt.NewTrie()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, input := range vector.inputs {
+			trie.Insert([]byte(input[0]), []byte(input[1]))
+		}
+		assert.Equal(t, trie.GetRootHash(), vector.rootHash, vector.name)
+	}
+
+	trie, _ := mpt.NewTrie()
+	trie.Insert([]byte("doe"), []byte("reindeer"))
+	trie.Insert([]byte("dog"), []byte("puppy"))
+	trie.Insert([]byte("dogglesworth"), []byte("cat"))
+	assert.Equal(t, trie.Size(), 3)
+	value, found := trie.Get([]byte("dog"))
+	assert.Assert(t, found)
+	assert.Equal(t, string(value), "puppy")
+	_, found = trie.Get([]byte("do"))
+	assert.Assert(t, !found)
+
+	// Deleting restores the former root
+	trie.Insert([]byte("dogs"), []byte("pack"))
+	assert.Equal(t, trie.Size(), 4)
+	assert.Assert(t, trie.Delete([]byte("dogs")))
+	assert.Assert(t, !trie.Delete([]byte("dogs")))
+	assert.Equal(t, trie.Size(), 3)
+	assert.Equal(t, trie.GetRootHash(), "8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3")
+
+	for _, key := range []string{"doe", "dog", "dogglesworth"} {
+		assert.Assert(t, trie.Delete([]byte(key)))
+	}
+	assert.Equal(t, trie.GetRootHash(), mpt.EMPTY_ROOT)
+	assert.Equal(t, trie.Size(), 0)
+}
@@ -0,0 +1,37 @@
+package mst
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// Entry is the liability towards a user: its data, eg. the hash of its identifier with a nonce, and its balance
+type Entry struct {
+	Data    []byte
+	Balance int64
+}
+
+// Node is a node of a Merkle sum tree, ie. a hash committing to the sum of the balances below it
+type Node struct {
+	Hash hash.Hash
+	Sum  int64
+}
+
+//--- utility
+
+// add returns the sum of the two passed non-negative balances, if it's non-negative and doesn't overflow
+func add(a, b int64) (sum int64, ok bool) {
+	if a < 0 || b < 0 || a > math.MaxInt64-b {
+		return
+	}
+	return a + b, true
+}
+
+// sumBytes returns the 8-byte big-endian encoding of the passed sum
+func sumBytes(sum int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(sum))
+	return b
+}
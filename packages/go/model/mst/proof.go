@@ -0,0 +1,86 @@
+package mst
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/merkle"
+)
+
+// Proof defines the Merkle sum tree proof that consists of the trail of intermediate nodes with their sums and the path, top-down as in `merkle.Proof`,
+// the name of the hashing engine used and the size of the tree at the date of the proof
+type Proof struct {
+	Trail []*Node
+	merkle.Path
+	Size   int
+	Engine string
+}
+
+// String returns the base64-encoded dot-separated concatenation of the hexadecimal hashes, their comma-separated sums, the path,
+// the engine and the size of the tree, eg. Base64Encode("<hash1><hash2>.150,1200.11.sha-256.4")
+func (p *Proof) String() string {
+	var hashes, sums []string
+	for _, n := range p.Trail {
+		hashes = append(hashes, utls.ToHex(n.Hash))
+		sums = append(sums, strconv.FormatInt(n.Sum, 10))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s.%s.%s.%s.%d",
+		strings.Join(hashes, ""), strings.Join(sums, ","), p.Path, p.Engine, p.Size)))
+}
+
+// ProofFrom builds a Merkle sum tree proof from the passed string, provided it's an actual stringified proof
+func ProofFrom(b64 string) (p *Proof, err error) {
+	str, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	parts := strings.Split(string(str), ".")
+	if len(parts) != 5 || !pathRegex.MatchString(parts[2]) {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	digestSize, e1 := hash.GetDigestSize(parts[3])
+	size, e2 := strconv.Atoi(parts[4])
+	hashes, e3 := utls.FromHex(parts[0])
+	if e1 != nil || e2 != nil || e3 != nil || size <= 0 || len(hashes) != len(parts[2])*digestSize {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	var sums []string
+	if parts[1] != "" {
+		sums = strings.Split(parts[1], ",")
+	}
+	if len(sums) != len(parts[2]) {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	p = &Proof{
+		Trail:  []*Node{},
+		Path:   parts[2],
+		Size:   size,
+		Engine: parts[3],
+	}
+	for i, s := range sums {
+		sum, e := strconv.ParseInt(s, 10, 64)
+		if e != nil || sum < 0 {
+			err = exception.NewInvalidMerkleProofError(b64)
+			return
+		}
+		p.Trail = append(p.Trail, &Node{
+			Hash: hashes[i*digestSize : (i+1)*digestSize],
+			Sum:  sum,
+		})
+	}
+	return
+}
+
+//--- utility
+
+var pathRegex = regexp.MustCompile(`^[01]*$`)
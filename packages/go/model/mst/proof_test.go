@@ -0,0 +1,75 @@
+package mst_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/mst"
+	"gotest.tools/assert"
+)
+
+// TestProof ...
+func TestProof(t *testing.T) {
+	tree, err := mst.NewTree(hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []*mst.Entry{}
+	for i := 0; i < 5; i++ {
+		entries = append(entries, &mst.Entry{Data: []byte{byte(i)}, Balance: int64(i * 10)})
+	}
+	if _, err = tree.AddLeaves(entries...); err != nil {
+		t.Fatal(err)
+	}
+	rootHash, _ := tree.GetRootHash()
+	total, _ := tree.GetTotal()
+	assert.Equal(t, total, int64(100))
+
+	proof, err := tree.GetProof(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, err := mst.ProofFrom(proof.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, instance.String(), proof.String())
+	assert.Equal(t, instance.Size, 5)
+	assert.Assert(t, tree.ValidateProof(instance, entries[3], rootHash, total))
+	_, err = tree.GetProof(5)
+	assert.Error(t, err, "index out of range: 5")
+
+	// Tampered sums are rejected
+	instance.Trail[0].Sum = -instance.Trail[0].Sum
+	assert.Assert(t, !tree.ValidateProof(instance, entries[3], rootHash, total))
+	instance.Trail[0].Sum = math.MaxInt64
+	assert.Assert(t, !tree.ValidateProof(instance, entries[3], rootHash, total))
+	_, err = mst.ProofFrom("Li0xLjEuc2hhLTI1Ni4y")
+	assert.ErrorContains(t, err, "invalid proof")
+}
+
+// TestTamperedSiblingSum ...
+func TestTamperedSiblingSum(t *testing.T) {
+	tree, _ := mst.NewTree()
+	alice, bob := &mst.Entry{Data: []byte("alice"), Balance: 10}, &mst.Entry{Data: []byte("bob"), Balance: 20}
+	proofs, err := tree.AddLeaves(alice, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, _ := tree.GetRootHash()
+	assert.Assert(t, tree.ValidateProof(proofs[0], alice, rootHash, 30))
+	assert.Assert(t, tree.ValidateProof(proofs[1], bob, rootHash, 30))
+
+	// Claiming a lower sibling sum to shrink the total, as in a tree only committing to the sum of the children
+	for _, sum := range []int64{0, 5} {
+		forged := &mst.Proof{
+			Trail:  []*mst.Node{{Hash: proofs[1].Trail[0].Hash, Sum: sum}},
+			Path:   proofs[1].Path,
+			Size:   2,
+			Engine: hash.SHA_256,
+		}
+		assert.Assert(t, !tree.ValidateProof(forged, bob, rootHash, 20+sum))
+		assert.Assert(t, !tree.ValidateProof(forged, bob, rootHash, 30))
+	}
+}
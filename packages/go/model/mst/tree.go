@@ -0,0 +1,233 @@
+package mst
+
+import (
+	"fmt"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/merkle"
+)
+
+//--- TYPES
+
+// Tree is a Merkle sum tree, eg. for proofs of liabilities: each leaf commits to the balance of a user, ie. `hash(data || balance)`,
+// and each node to the balances of both its children, ie. `hash(left || leftSum || right || rightSum)`, carrying their sum, so that the root commits
+// to the total of all balances and no sibling sum can be altered in a proof.
+// As in `merkle.Tree`, unpaired nodes are promoted to the next level unchanged.
+// NB: sums are hashed as 8-byte big-endian integers, and negative balances or overflowing sums are rejected.
+type Tree struct {
+	engine       string
+	hashFunction hash.Function
+	levels       [][]*Node
+}
+
+//--- METHODS
+
+// AddLeaves sets the leaves of the tree from the passed entries, replacing any existing leaf, and returns the proofs of all leaves
+func (t *Tree) AddLeaves(entries ...*Entry) (proofs []*Proof, err error) {
+	if len(entries) == 0 {
+		err = fmt.Errorf("empty tree")
+		return
+	}
+	leaves := []*Node{}
+	for i, entry := range entries {
+		if entry == nil || entry.Balance < 0 {
+			err = fmt.Errorf("invalid entry: %v", entry)
+			return
+		}
+		leaf := t.hashLeaf(entry)
+		if !hash.IsCorrect(leaf.Hash, t.engine) {
+			err = fmt.Errorf("invalid entry at index %d: unable to hash it with %s", i, t.engine)
+			return
+		}
+		leaves = append(leaves, leaf)
+	}
+	levels := [][]*Node{leaves}
+	for nodes := leaves; len(nodes) > 1; {
+		parents := []*Node{}
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				parents = append(parents, nodes[i])
+				continue
+			}
+			parent, ok := t.hashNode(nodes[i], nodes[i+1])
+			if !ok {
+				err = fmt.Errorf("sum overflow")
+				return
+			}
+			parents = append(parents, parent)
+		}
+		levels = append(levels, parents)
+		nodes = parents
+	}
+	t.levels = levels
+	for index := range leaves {
+		proofs = append(proofs, t.proofAt(index))
+	}
+	return
+}
+
+// Depth returns the number of levels of the tree excluding the root
+func (t *Tree) Depth() (depth int, err error) {
+	if len(t.levels) == 0 {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	depth = len(t.levels) - 1
+	return
+}
+
+// GetEngine returns the name of the used hashing function
+func (t *Tree) GetEngine() string {
+	return t.engine
+}
+
+// GetProof returns the proof of the leaf at the passed index
+func (t *Tree) GetProof(index int) (p *Proof, err error) {
+	if index < 0 || index >= t.Size() {
+		err = fmt.Errorf("index out of range: %d", index)
+		return
+	}
+	p = t.proofAt(index)
+	return
+}
+
+// GetRootHash returns the hexadecimal representation of the root hash, which commits to the total
+func (t *Tree) GetRootHash() (rootHash string, err error) {
+	if len(t.levels) == 0 {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	rootHash = utls.ToHex(t.root().Hash)
+	return
+}
+
+// GetTotal returns the sum of all balances, ie. the total to publish along with the root hash
+func (t *Tree) GetTotal() (total int64, err error) {
+	if len(t.levels) == 0 {
+		err = exception.NewTreeNotBuiltError()
+		return
+	}
+	total = t.root().Sum
+	return
+}
+
+// HashLeaf returns the leaf of the passed entry, ie. `hash(data || balance)` with its balance as sum
+func (t *Tree) HashLeaf(entry *Entry) *Node {
+	return t.hashLeaf(entry)
+}
+
+// Size returns the number of leaves
+func (t *Tree) Size() int {
+	if len(t.levels) == 0 {
+		return 0
+	}
+	return len(t.levels[0])
+}
+
+// ValidateProof checks that the passed proof shows the balance of the passed entry counted toward the passed total of the passed root hash,
+// rejecting any negative or overflowing sum
+func (t *Tree) ValidateProof(proof *Proof, entry *Entry, rootHash string, total int64) bool {
+	if proof == nil || entry == nil || entry.Balance < 0 || proof.Engine != t.engine || len(proof.Path) != len(proof.Trail) {
+		return false
+	}
+	node := t.hashLeaf(entry)
+	// Bottom-up
+	for idx := len(proof.Trail) - 1; idx >= 0; idx-- {
+		sibling := proof.Trail[idx]
+		if sibling == nil || sibling.Sum < 0 {
+			return false
+		}
+		var ok bool
+		if string(proof.Path[idx]) == merkle.RIGHT {
+			node, ok = t.hashNode(sibling, node)
+		} else {
+			node, ok = t.hashNode(node, sibling)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return node.Sum == total && utls.ToHex(node.Hash) == rootHash
+}
+
+// For internal use only
+
+func (t *Tree) hashLeaf(entry *Entry) *Node {
+	return &Node{
+		Hash: t.hashFunction(append(append([]byte{}, entry.Data...), sumBytes(entry.Balance)...)),
+		Sum:  entry.Balance,
+	}
+}
+
+// hashNode returns the parent of the passed nodes, unless their sum is negative or overflows
+func (t *Tree) hashNode(left, right *Node) (parent *Node, ok bool) {
+	sum, ok := add(left.Sum, right.Sum)
+	if !ok {
+		return
+	}
+	data := append(append([]byte{}, left.Hash...), sumBytes(left.Sum)...)
+	data = append(append(data, right.Hash...), sumBytes(right.Sum)...)
+	parent = &Node{
+		Hash: t.hashFunction(data),
+		Sum:  sum,
+	}
+	return
+}
+
+func (t *Tree) proofAt(index int) *Proof {
+	trail := []*Node{}
+	var path merkle.Path
+	for level := 0; level < len(t.levels)-1; level++ {
+		nodes := t.levels[level]
+		if index%2 == 1 {
+			trail = append([]*Node{nodes[index-1]}, trail...)
+			path = merkle.RIGHT + path
+		} else if index+1 < len(nodes) {
+			trail = append([]*Node{nodes[index+1]}, trail...)
+			path = merkle.LEFT + path
+		}
+		index /= 2
+	}
+	return &Proof{
+		Trail:  trail,
+		Path:   path,
+		Size:   t.Size(),
+		Engine: t.engine,
+	}
+}
+
+func (t *Tree) root() *Node {
+	return t.levels[len(t.levels)-1][0]
+}
+
+//--- FUNCTIONS
+
+// NewTree instantiates a new empty Merkle sum tree using the passed engine, SHA-256 by default.
+// Engines unable to hash the nodes with their sums, eg. `hash.POSEIDON_BN254`, are rejected.
+func NewTree(engine ...string) (t *Tree, err error) {
+	usingEngine := hash.SHA_256
+	if len(engine) == 1 && engine[0] != "" {
+		usingEngine = engine[0]
+	}
+	fn, err := hash.BuildFunction(usingEngine)
+	if err != nil {
+		return
+	}
+	digestSize, err := hash.GetDigestSize(usingEngine)
+	if err != nil {
+		return
+	}
+	tree := &Tree{
+		engine:       usingEngine,
+		hashFunction: fn,
+	}
+	empty := &Node{Hash: make(hash.Hash, digestSize)}
+	if node, _ := tree.hashNode(empty, empty); !hash.IsCorrect(node.Hash, usingEngine) {
+		err = exception.NewInvalidEngineError(fmt.Sprintf("%s can't hash nodes with their sums", usingEngine))
+		return
+	}
+	t = tree
+	return
+}
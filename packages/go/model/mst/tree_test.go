@@ -0,0 +1,75 @@
+package mst_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/mst"
+	"gotest.tools/assert"
+)
+
+var sha256, _ = hash.BuildFunction(hash.SHA_256)
+
+func withSum(data []byte, sum int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(sum))
+	return append(append([]byte{}, data...), b...)
+}
+
+// TestMerkleSumTree ...
+func TestMerkleSumTree(t *testing.T) {
+	tree, err := mst.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tree.GetRootHash()
+	assert.Error(t, err, "tree not built")
+
+	entries := []*mst.Entry{
+		{Data: []byte("alice"), Balance: 100},
+		{Data: []byte("bob"), Balance: 50},
+		{Data: []byte("carol"), Balance: 25},
+	}
+	proofs, err := tree.AddLeaves(entries...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(proofs), 3)
+	assert.Equal(t, tree.Size(), 3)
+	depth, _ := tree.Depth()
+	assert.Equal(t, depth, 2)
+
+	// hash(left || leftSum || right || rightSum) with carol promoted
+	alice, bob, carol := sha256(withSum([]byte("alice"), 100)), sha256(withSum([]byte("bob"), 50)), sha256(withSum([]byte("carol"), 25))
+	ab := sha256(append(withSum(alice, 100), withSum(bob, 50)...))
+	root := sha256(append(withSum(ab, 150), withSum(carol, 25)...))
+	rootHash, _ := tree.GetRootHash()
+	assert.Equal(t, rootHash, utls.ToHex(root))
+	total, _ := tree.GetTotal()
+	assert.Equal(t, total, int64(175))
+	assert.Equal(t, utls.ToHex(tree.HashLeaf(entries[1]).Hash), utls.ToHex(bob))
+
+	for i, entry := range entries {
+		assert.Assert(t, tree.ValidateProof(proofs[i], entry, rootHash, total))
+	}
+	assert.Equal(t, proofs[2].Path, "0")
+	assert.Equal(t, proofs[2].Trail[0].Sum, int64(150))
+
+	// Wrong balance or total
+	assert.Assert(t, !tree.ValidateProof(proofs[0], &mst.Entry{Data: []byte("alice"), Balance: 10}, rootHash, total))
+	assert.Assert(t, !tree.ValidateProof(proofs[0], entries[0], rootHash, 200))
+
+	// Negative balances and overflowing sums
+	_, err = tree.AddLeaves(&mst.Entry{Data: []byte("mallory"), Balance: -1})
+	assert.ErrorContains(t, err, "invalid entry")
+	_, err = tree.AddLeaves(&mst.Entry{Data: []byte("a"), Balance: math.MaxInt64}, &mst.Entry{Data: []byte("b"), Balance: 1})
+	assert.Error(t, err, "sum overflow")
+	rootHash, _ = tree.GetRootHash()
+	assert.Equal(t, rootHash, utls.ToHex(root))
+	// Engines that can't hash the nodes with their sums would only give empty roots
+	_, err = mst.NewTree(hash.POSEIDON_BN254)
+	assert.Error(t, err, "invalid engine: poseidon-bn254 can't hash nodes with their sums")
+}
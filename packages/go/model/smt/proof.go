@@ -0,0 +1,77 @@
+package smt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+)
+
+// Proof is the compressed inclusion proof of a key and its value in a sparse Merkle tree, or its non-inclusion proof if `Included` is `false`.
+// Only non-empty siblings are part of it, bottom-up, the bit of `Bitmap` at each height (least significant bit first) telling whether the sibling
+// is one of them or the default hash of an empty subtree.
+type Proof struct {
+	Key      hash.Hash
+	Included bool
+	Value    []byte
+	Bitmap   []byte
+	Siblings hash.Hashes
+	Engine   string
+}
+
+// String returns the base64-encoded dot-separated concatenation of the hexadecimal key, the inclusion flag, the hexadecimal value,
+// bitmap and siblings, and the engine, eg. Base64Encode("<key>.1.<value>.<bitmap>.<sibling1><sibling2>.sha-256")
+func (p *Proof) String() string {
+	included := "0"
+	if p.Included {
+		included = "1"
+	}
+	var siblings []string
+	for _, s := range p.Siblings {
+		siblings = append(siblings, utls.ToHex(s))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s.%s.%s.%s.%s.%s",
+		utls.ToHex(p.Key), included, utls.ToHex(p.Value), utls.ToHex(p.Bitmap), strings.Join(siblings, ""), p.Engine)))
+}
+
+// ProofFrom builds a sparse Merkle tree proof from the passed string, provided it's an actual stringified proof
+func ProofFrom(b64 string) (p *Proof, err error) {
+	str, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	parts := strings.Split(string(str), ".")
+	if len(parts) != 6 || (parts[1] != "0" && parts[1] != "1") {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	digestSize, err := hash.GetDigestSize(parts[5])
+	if err != nil {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	key, e1 := utls.FromHex(parts[0])
+	value, e2 := utls.FromHex(parts[2])
+	bitmap, e3 := utls.FromHex(parts[3])
+	siblings, e4 := utls.FromHex(parts[4])
+	if e1 != nil || e2 != nil || e3 != nil || e4 != nil || len(key) != digestSize || len(siblings)%digestSize != 0 {
+		err = exception.NewInvalidMerkleProofError(b64)
+		return
+	}
+	p = &Proof{
+		Key:      key,
+		Included: parts[1] == "1",
+		Value:    value,
+		Bitmap:   bitmap,
+		Siblings: hash.Hashes{},
+		Engine:   parts[5],
+	}
+	for i := 0; i < len(siblings); i += digestSize {
+		p.Siblings = append(p.Siblings, siblings[i:i+digestSize])
+	}
+	return
+}
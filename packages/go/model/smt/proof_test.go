@@ -0,0 +1,44 @@
+package smt_test
+
+import (
+	"testing"
+
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/smt"
+	"gotest.tools/assert"
+)
+
+// TestProof ...
+func TestProof(t *testing.T) {
+	tree, err := smt.NewTree(hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blake2b, err := hash.BuildFunction(hash.BLAKE2B_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob", "carol", "dave"} {
+		_ = tree.Set(blake2b([]byte(name)), []byte(name))
+	}
+	rootHash := tree.GetRootHash()
+
+	for _, name := range []string{"bob", "eve"} {
+		proof, err := tree.GetProof(blake2b([]byte(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		instance, err := smt.ProofFrom(proof.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, instance.Engine, hash.BLAKE2B_256)
+		assert.Equal(t, instance.Included, proof.Included)
+		assert.Equal(t, len(instance.Siblings), len(proof.Siblings))
+		assert.Equal(t, instance.String(), proof.String())
+		assert.Assert(t, tree.ValidateProof(instance, rootHash))
+	}
+
+	_, err = smt.ProofFrom("not-a-valid-proof")
+	assert.Error(t, err, "invalid proof: not-a-valid-proof")
+}
@@ -0,0 +1,245 @@
+package smt
+
+import (
+	"bytes"
+	"fmt"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/exception"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/merkle"
+)
+
+//--- TYPES
+
+// Tree is a sparse Merkle tree committing to key/value pairs, where keys are hashes of the used engine giving the path to their leaf,
+// eg. a 256-level tree for a 32-byte digest engine like SHA-256.
+// Leaves and nodes are hashed with the RFC 6962 prefixes, ie. `hash(0x00 || key || value)` and `hash(0x01 || left || right)`.
+// Only non-empty nodes are stored, all empty subtrees using precomputed default hashes.
+type Tree struct {
+	engine       string
+	hashFunction hash.Function
+	depth        int
+	defaults     hash.Hashes
+	nodes        map[string]hash.Hash
+	values       map[string][]byte
+}
+
+//--- METHODS
+
+// Delete removes the passed key from the tree, if it exists
+func (t *Tree) Delete(key hash.Hash) error {
+	if !t.isKey(key) {
+		return exception.NewInvalidKeyError(utls.ToHex(key))
+	}
+	delete(t.values, string(key))
+	t.update(key, t.defaults[0])
+	return nil
+}
+
+// Depth returns the number of levels of the tree excluding the root, ie. the length in bits of its keys
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Get returns the value set for the passed key, if any
+func (t *Tree) Get(key hash.Hash) (value []byte, found bool) {
+	value, found = t.values[string(key)]
+	return
+}
+
+// GetEngine returns the name of the used hashing function
+func (t *Tree) GetEngine() string {
+	return t.engine
+}
+
+// GetProof returns the inclusion proof of the passed key if it exists in the tree, its non-inclusion proof otherwise
+func (t *Tree) GetProof(key hash.Hash) (p *Proof, err error) {
+	if !t.isKey(key) {
+		err = exception.NewInvalidKeyError(utls.ToHex(key))
+		return
+	}
+	value, found := t.values[string(key)]
+	bitmap := make([]byte, (t.depth+7)/8)
+	siblings := hash.Hashes{}
+	for height := 0; height < t.depth; height++ {
+		sibling := t.node(height, flip(key, t.depth-1-height))
+		if !bytes.Equal(sibling, t.defaults[height]) {
+			bitmap[height/8] |= 1 << (height % 8)
+			siblings = append(siblings, sibling)
+		}
+	}
+	p = &Proof{
+		Key:      key,
+		Included: found,
+		Value:    value,
+		Bitmap:   bitmap,
+		Siblings: siblings,
+		Engine:   t.engine,
+	}
+	return
+}
+
+// GetRootHash returns the hexadecimal representation of the root hash of the tree
+func (t *Tree) GetRootHash() string {
+	return utls.ToHex(t.node(t.depth, nil))
+}
+
+// Set sets the passed value for the passed key
+func (t *Tree) Set(key hash.Hash, value []byte) error {
+	if !t.isKey(key) {
+		return exception.NewInvalidKeyError(utls.ToHex(key))
+	}
+	t.values[string(key)] = value
+	t.update(key, t.hashLeaf(key, value))
+	return nil
+}
+
+// Size returns the number of keys in the tree
+func (t *Tree) Size() int {
+	return len(t.values)
+}
+
+// ValidateProof checks that the passed inclusion or non-inclusion proof matches the passed root hash
+func (t *Tree) ValidateProof(p *Proof, rootHash string) bool {
+	if p == nil || p.Engine != t.engine || !t.isKey(p.Key) || len(p.Bitmap) != (t.depth+7)/8 {
+		return false
+	}
+	h := t.defaults[0]
+	if p.Included {
+		h = t.hashLeaf(p.Key, p.Value)
+	}
+	siblings := p.Siblings
+	for height := 0; height < t.depth; height++ {
+		sibling := t.defaults[height]
+		if p.Bitmap[height/8]&(1<<(height%8)) != 0 {
+			if len(siblings) == 0 {
+				return false
+			}
+			sibling, siblings = siblings[0], siblings[1:]
+		}
+		if bit(p.Key, t.depth-1-height) == 0 {
+			h = t.hashNode(h, sibling)
+		} else {
+			h = t.hashNode(sibling, h)
+		}
+	}
+	return len(siblings) == 0 && utls.ToHex(h) == rootHash
+}
+
+// For internal use only
+
+// hashLeaf returns `hash(0x00 || key || value)`, the RFC 6962 leaf prefix preventing any leaf from being passed off as a node
+func (t *Tree) hashLeaf(key hash.Hash, value []byte) hash.Hash {
+	return t.hashFunction(append(append([]byte{merkle.LEAF_PREFIX}, key...), value...))
+}
+
+// hashNode returns `hash(0x01 || left || right)`
+func (t *Tree) hashNode(left, right hash.Hash) hash.Hash {
+	return t.hashFunction(append(append([]byte{merkle.NODE_PREFIX}, left...), right...))
+}
+
+func (t *Tree) isKey(key hash.Hash) bool {
+	return len(key)*8 == t.depth
+}
+
+// node returns the node at the passed height on the path of the passed key, or the default hash of the height if it's empty
+func (t *Tree) node(height int, key hash.Hash) hash.Hash {
+	if n, found := t.nodes[nodeID(height, key, t.depth)]; found {
+		return n
+	}
+	return t.defaults[height]
+}
+
+// update sets the passed leaf at the passed key and recomputes all its ancestors, only keeping non-empty nodes
+func (t *Tree) update(key hash.Hash, leaf hash.Hash) {
+	h := leaf
+	for height := 0; height <= t.depth; height++ {
+		id := nodeID(height, key, t.depth)
+		if bytes.Equal(h, t.defaults[height]) {
+			delete(t.nodes, id)
+		} else {
+			t.nodes[id] = h
+		}
+		if height == t.depth {
+			break
+		}
+		sibling := t.node(height, flip(key, t.depth-1-height))
+		if bit(key, t.depth-1-height) == 0 {
+			h = t.hashNode(h, sibling)
+		} else {
+			h = t.hashNode(sibling, h)
+		}
+	}
+}
+
+//--- FUNCTIONS
+
+// NewTree instantiates a new empty sparse Merkle tree using the passed engine (SHA-256 by default), its depth being the length in bits of its digests.
+// Engines unable to hash the prefixed leaves and nodes, eg. `hash.POSEIDON_BN254`, are rejected.
+func NewTree(engine ...string) (t *Tree, err error) {
+	usingEngine := hash.SHA_256
+	if len(engine) == 1 && engine[0] != "" {
+		usingEngine = engine[0]
+	}
+	fn, err := hash.BuildFunction(usingEngine)
+	if err != nil {
+		return
+	}
+	digestSize, err := hash.GetDigestSize(usingEngine)
+	if err != nil {
+		return
+	}
+	tree := &Tree{
+		engine:       usingEngine,
+		hashFunction: fn,
+		depth:        digestSize * 8,
+		nodes:        make(map[string]hash.Hash),
+		values:       make(map[string][]byte),
+	}
+	tree.defaults = defaultHashes(tree)
+	for _, h := range append(hash.Hashes{tree.hashLeaf(tree.defaults[0], nil)}, tree.defaults[1:]...) {
+		if !hash.IsCorrect(h, usingEngine) {
+			err = exception.NewInvalidEngineError(fmt.Sprintf("%s can't hash prefixed leaves and nodes", usingEngine))
+			return
+		}
+	}
+	t = tree
+	return
+}
+
+//--- utility
+
+// defaultHashes returns the root hashes of empty subtrees at each height, the empty leaf being a zero digest
+func defaultHashes(t *Tree) hash.Hashes {
+	defaults := hash.Hashes{make(hash.Hash, t.depth/8)}
+	for height := 1; height <= t.depth; height++ {
+		defaults = append(defaults, t.hashNode(defaults[height-1], defaults[height-1]))
+	}
+	return defaults
+}
+
+// bit returns the bit of the passed key at the passed index, starting from its most significant bit
+func bit(key hash.Hash, index int) byte {
+	return (key[index/8] >> (7 - index%8)) & 1
+}
+
+func flip(key hash.Hash, index int) hash.Hash {
+	flipped := append(hash.Hash{}, key...)
+	flipped[index/8] ^= 1 << (7 - index%8)
+	return flipped
+}
+
+// nodeID identifies a node by its height and the prefix of the keys below it
+func nodeID(height int, key hash.Hash, depth int) string {
+	prefixSize := depth - height
+	id := []byte{byte(height >> 8), byte(height)}
+	if prefixSize == 0 {
+		return string(id)
+	}
+	prefix := append(hash.Hash{}, key[:(prefixSize+7)/8]...)
+	if prefixSize%8 != 0 {
+		prefix[len(prefix)-1] &= 0xff << (8 - prefixSize%8)
+	}
+	return string(append(id, prefix...))
+}
@@ -0,0 +1,144 @@
+package smt_test
+
+import (
+	"testing"
+
+	utls "github.com/cyrildever/go-utls/common/utils"
+	"github.com/cyrildever/merkle-trees/packages/go/model/hash"
+	"github.com/cyrildever/merkle-trees/packages/go/model/smt"
+	"gotest.tools/assert"
+)
+
+var sha256, _ = hash.BuildFunction(hash.SHA_256)
+
+// TestSparseMerkleTree ...
+func TestSparseMerkleTree(t *testing.T) {
+	tree, err := smt.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree.Depth(), 256)
+	assert.Equal(t, tree.GetEngine(), hash.SHA_256)
+
+	// The root of the empty tree is the default hash of the root level
+	empty := make([]byte, 32)
+	for i := 0; i < 256; i++ {
+		empty = sha256(append(append([]byte{0x01}, empty...), empty...))
+	}
+	emptyRootHash := tree.GetRootHash()
+	assert.Equal(t, emptyRootHash, utls.ToHex(empty))
+
+	alice, bob := sha256([]byte("alice")), sha256([]byte("bob"))
+	if err = tree.Set(alice, []byte("100")); err != nil {
+		t.Fatal(err)
+	}
+	if err = tree.Set(bob, []byte("50")); err != nil {
+		t.Fatal(err)
+	}
+	value, found := tree.Get(alice)
+	assert.Assert(t, found)
+	assert.Equal(t, string(value), "100")
+	assert.Equal(t, tree.Size(), 2)
+	rootHash := tree.GetRootHash()
+
+	// The root only depends on the content of the tree
+	other, err := smt.NewTree(hash.SHA_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = other.Set(bob, []byte("50"))
+	_ = other.Set(alice, []byte("0"))
+	_ = other.Set(alice, []byte("100"))
+	assert.Equal(t, other.GetRootHash(), rootHash)
+
+	if err = tree.Delete(bob); err != nil {
+		t.Fatal(err)
+	}
+	_, found = tree.Get(bob)
+	assert.Assert(t, !found)
+	assert.Assert(t, tree.GetRootHash() != rootHash)
+	if err = tree.Delete(alice); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, tree.GetRootHash(), emptyRootHash)
+	assert.Equal(t, tree.Size(), 0)
+
+	err = tree.Set([]byte("alice"), []byte("100"))
+	assert.Error(t, err, "invalid key: 616c696365")
+
+	sha512, err := smt.NewTree(hash.SHA_512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, sha512.Depth(), 512)
+	_, err = smt.NewTree("wrong-engine")
+	assert.Error(t, err, "invalid engine: wrong-engine")
+
+	// Leaves and nodes are domain-separated, so that a 32-byte key with a 32-byte value can't pass for a node
+	single, _ := smt.NewTree()
+	key := sha256([]byte("alice"))
+	_ = single.Set(key, sha256([]byte("100")))
+	h := sha256(append(append([]byte{0x00}, key...), sha256([]byte("100"))...))
+	defaults := [][]byte{make([]byte, 32)}
+	for height := 0; height < 256; height++ {
+		if (key[(255-height)/8]>>(7-(255-height)%8))&1 == 0 {
+			h = sha256(append(append([]byte{0x01}, h...), defaults[height]...))
+		} else {
+			h = sha256(append(append([]byte{0x01}, defaults[height]...), h...))
+		}
+		defaults = append(defaults, sha256(append(append([]byte{0x01}, defaults[height]...), defaults[height]...)))
+	}
+	assert.Equal(t, single.GetRootHash(), utls.ToHex(h))
+
+	// Engines that can't hash the prefixed nodes would only give empty roots
+	_, err = smt.NewTree(hash.POSEIDON_BN254)
+	assert.Error(t, err, "invalid engine: poseidon-bn254 can't hash prefixed leaves and nodes")
+}
+
+// TestSparseMerkleTreeProof ...
+func TestSparseMerkleTreeProof(t *testing.T) {
+	tree, err := smt.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob, carol := sha256([]byte("alice")), sha256([]byte("bob")), sha256([]byte("carol"))
+	_ = tree.Set(alice, []byte("100"))
+	_ = tree.Set(bob, []byte("50"))
+	rootHash := tree.GetRootHash()
+
+	inclusion, err := tree.GetProof(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, inclusion.Included)
+	assert.Equal(t, string(inclusion.Value), "100")
+	assert.Equal(t, len(inclusion.Siblings), 1) // Only bob's subtree isn't empty
+	assert.Assert(t, tree.ValidateProof(inclusion, rootHash))
+
+	inclusion.Value = []byte("1000")
+	assert.Assert(t, !tree.ValidateProof(inclusion, rootHash))
+
+	// Proof of absence
+	nonInclusion, err := tree.GetProof(carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, !nonInclusion.Included)
+	assert.Assert(t, tree.ValidateProof(nonInclusion, rootHash))
+	nonInclusion.Included = true
+	assert.Assert(t, !tree.ValidateProof(nonInclusion, rootHash))
+	nonInclusion.Included = false
+
+	// Any empty tree may verify a proof
+	verifier, err := smt.NewTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Assert(t, verifier.ValidateProof(nonInclusion, rootHash))
+
+	_ = tree.Set(carol, []byte("10"))
+	assert.Assert(t, !tree.ValidateProof(nonInclusion, tree.GetRootHash()))
+
+	_, err = tree.GetProof([]byte("carol"))
+	assert.Error(t, err, "invalid key: 6361726f6c")
+}